@@ -0,0 +1,39 @@
+package terralu
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformBlockStorageConfig generates the Terraform configuration for a block storage volume and its attachment
+func (t *TerraluImpl) GenerateTerraformBlockStorageConfig(volume *BlockStorageInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(volume)
+	if err != nil {
+		return "", fmt.Errorf("error validating the block storage instance: %w", err)
+	}
+	if volume.AttachTo != nil && volume.AttachTo.ID == "" && volume.AttachTo.Resource == "" {
+		return "", fmt.Errorf("volume %q attachment has no virtual machine", volume.Name)
+	}
+
+	volumeResource := t.newResource("mgc_block_storage_volumes", volume.Name)
+	volumeResource.Body.
+		SetAttribute("name", hcl.String(volume.Name)).
+		SetAttribute("size", hcl.Number(volume.Size)).
+		SetAttribute("type", hcl.Object{
+			{Key: "name", Value: hcl.String(volume.Type)},
+		})
+	blocks := []*hcl.Block{volumeResource}
+
+	if volume.AttachTo != nil {
+		attachment := t.newResource("mgc_block_storage_volume_attachment", volume.Name)
+		attachment.Body.
+			SetAttribute("block_storage_id", hcl.Raw("mgc_block_storage_volumes."+resourceName(volume.Name)+".id")).
+			SetAttribute("virtual_machine_id", volume.AttachTo.Expression())
+		blocks = append(blocks, attachment)
+	}
+
+	return t.write(blocks...)
+}
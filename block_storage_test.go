@@ -0,0 +1,131 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformBlockStorageConfig tests the GenerateTerraformBlockStorageConfig method
+func TestTerraluImpl_GenerateTerraformBlockStorageConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	vm := &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{Name: "web"},
+	}
+	tests := []struct {
+		name    string
+		volume  *BlockStorageInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Detached Volume",
+			volume: &BlockStorageInstance{
+				Name: "data",
+				Size: 50,
+				Type: "cloud_nvme1k",
+			},
+			want: `resource "mgc_block_storage_volumes" "data" {
+			  provider = mgc.test
+			  name     = "data"
+			  size     = 50
+			  type     = {
+			    name = "cloud_nvme1k"
+			  }
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Volume Attached To Generated VM",
+			volume: &BlockStorageInstance{
+				Name:     "data",
+				Size:     50,
+				Type:     "cloud_nvme1k",
+				AttachTo: vm.Reference(),
+			},
+			want: `resource "mgc_block_storage_volumes" "data" {
+			  provider = mgc.test
+			  name     = "data"
+			  size     = 50
+			  type     = {
+			    name = "cloud_nvme1k"
+			  }
+			}
+
+			resource "mgc_block_storage_volume_attachment" "data" {
+			  provider           = mgc.test
+			  block_storage_id   = mgc_block_storage_volumes.data.id
+			  virtual_machine_id = mgc_virtual_machine_instances.web.id
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Volume Attached To Existing VM",
+			volume: &BlockStorageInstance{
+				Name:     "data",
+				Size:     50,
+				Type:     "cloud_nvme1k",
+				AttachTo: &VirtualMachineSchema{ID: "vm-123"},
+			},
+			want: `resource "mgc_block_storage_volumes" "data" {
+			  provider = mgc.test
+			  name     = "data"
+			  size     = 50
+			  type     = {
+			    name = "cloud_nvme1k"
+			  }
+			}
+
+			resource "mgc_block_storage_volume_attachment" "data" {
+			  provider           = mgc.test
+			  block_storage_id   = mgc_block_storage_volumes.data.id
+			  virtual_machine_id = "vm-123"
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Empty Attachment",
+			volume: &BlockStorageInstance{
+				Name:     "data",
+				Size:     50,
+				Type:     "cloud_nvme1k",
+				AttachTo: &VirtualMachineSchema{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing Size",
+			volume: &BlockStorageInstance{
+				Name: "data",
+				Type: "cloud_nvme1k",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformBlockStorageConfig(tt.volume)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/joaogabriel01/terralu"
+)
+
+const usage = `Usage:
+  terralu [-workspaces dir]                 start the interactive interface
+  terralu init -workspace name -region r -alias a [-api-key k] [-key-id id] [-key-secret s] [-layout single|multi] [-env-credentials]
+  terralu vm add -workspace name -name n -machine-type t -image i -ssh-key k [-user-data file] [-count n] [-public-ip] [-skip-outputs]
+  terralu vm list -workspace name
+  terralu vm remove -workspace name -name n
+  terralu render -workspace name
+  terralu compile -workspace name -file stack.yaml [-layout single|multi] [-format hcl|json] [-env-credentials]
+  terralu compile -dry-run -file stack.yaml [-format hcl|json]
+  terralu run init|plan|apply|destroy -workspace name [-binary path] [-auto-approve]
+  terralu schema [-type stack|provider|vm|network|security-group|ssh-key|database|bucket]
+
+The credentials can also be given with the MGC_API_KEY, MGC_KEY_ID and MGC_KEY_SECRET environment variables,
+which keeps them out of the shell history and out of stack files. Every command accepts -workspaces to change the workspace root.
+`
+
+// errUsage is returned when the command line cannot be understood
+var errUsage = errors.New("invalid usage, run terralu help")
+
+// runCommand runs a non-interactive subcommand and writes its result to stdout
+func runCommand(args []string, stdout io.Writer) error {
+	switch args[0] {
+	case "init":
+		return initCommand(args[1:], stdout)
+	case "vm":
+		if len(args) < 2 {
+			return errUsage
+		}
+		switch args[1] {
+		case "add":
+			return vmAddCommand(args[2:], stdout)
+		case "list":
+			return vmListCommand(args[2:], stdout)
+		case "remove":
+			return vmRemoveCommand(args[2:], stdout)
+		}
+		return errUsage
+	case "render":
+		return renderCommand(args[1:], stdout)
+	case "compile":
+		return compileCommand(args[1:], stdout)
+	case "run":
+		if len(args) < 2 {
+			return errUsage
+		}
+		return runTerraformCommand(args[1], args[2:], stdout)
+	case "schema":
+		return schemaCommand(args[1:], stdout)
+	case "help", "-h", "-help", "--help":
+		_, err := io.WriteString(stdout, usage)
+		return err
+	}
+	return errUsage
+}
+
+// workspaceFlags holds the flags every subcommand shares
+type workspaceFlags struct {
+	root string
+	name string
+}
+
+// newFlagSet creates the flag set of a subcommand with the shared workspace flags
+func newFlagSet(name string) (*flag.FlagSet, *workspaceFlags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	shared := &workspaceFlags{}
+	defaultRoot, _ := terralu.DefaultWorkspaceRoot()
+	flags.StringVar(&shared.root, "workspaces", defaultRoot, "directory where workspaces are kept")
+	flags.StringVar(&shared.name, "workspace", "", "workspace name")
+	return flags, shared
+}
+
+// parse parses the flags and checks that the required flags are set
+func parse(flags *flag.FlagSet, args []string, required ...string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Name(), err)
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%s: unexpected argument %q", flags.Name(), flags.Arg(0))
+	}
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("%s: -%s is required", flags.Name(), name)
+		}
+	}
+	return nil
+}
+
+// open loads the workspace selected by the shared flags
+func (w *workspaceFlags) open() (terralu.Terralu, error) {
+	return terralu.NewWorkspaceManager(w.root).Open(w.name)
+}
+
+// initCommand creates a workspace with its provider configuration
+func initCommand(args []string, stdout io.Writer) error {
+	flags, shared := newFlagSet("init")
+	info := terralu.TerraluProviderInfo{}
+	flags.StringVar(&info.Region, "region", "", "Magalu Cloud region, such as br-se1")
+	flags.StringVar(&info.Alias, "alias", "", "provider alias")
+	flags.StringVar(&info.ApiKey, "api-key", os.Getenv("MGC_API_KEY"), "API key (default $MGC_API_KEY)")
+	flags.StringVar(&info.KeyID, "key-id", os.Getenv("MGC_KEY_ID"), "object storage key ID (default $MGC_KEY_ID)")
+	flags.StringVar(&info.KeySecret, "key-secret", os.Getenv("MGC_KEY_SECRET"), "object storage key secret (default $MGC_KEY_SECRET)")
+	layout := flags.String("layout", "single", "file layout, single or multi")
+	environmentCredentials := flags.Bool("env-credentials", false, "read the credentials from TF_VAR_ variables instead of terraform.tfvars")
+	err := parse(flags, args, "workspace", "region", "alias", "api-key")
+	if err != nil {
+		return err
+	}
+
+	opts, err := workspaceOptions("init", *layout, *environmentCredentials)
+	if err != nil {
+		return err
+	}
+
+	manager := terralu.NewWorkspaceManager(shared.root)
+	_, err = manager.Create(shared.name, &info, opts...)
+	if err != nil {
+		return err
+	}
+	path, err := manager.Path(shared.name)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, path)
+	if err != nil {
+		return err
+	}
+	if *environmentCredentials {
+		_, err = io.WriteString(stdout, terralu.EnvironmentInstructions(&info))
+	}
+	return err
+}
+
+// vmAddCommand adds a VM, or a fleet of identical VMs, to the workspace and prints its manifest
+func vmAddCommand(args []string, stdout io.Writer) error {
+	flags, shared := newFlagSet("vm add")
+	vm := terralu.VirtualMachineInstance{}
+	name := flags.String("name", "", "VM name")
+	machineType := flags.String("machine-type", "", "machine type, such as BV1-1-10")
+	image := flags.String("image", "", "image name, such as cloud-ubuntu-22.04 LTS")
+	flags.StringVar(&vm.RequiredFields.SSHKeyName, "ssh-key", "", "name of an SSH key registered in Magalu Cloud")
+	flags.StringVar(&vm.OptionalFields.UserDataPath, "user-data", "", "cloud-config file passed as user data")
+	flags.IntVar(&vm.OptionalFields.Count, "count", 0, "number of identical VMs to create")
+	flags.BoolVar(&vm.OptionalFields.NameIsPrefix, "name-is-prefix", false, "let the provider append a random suffix to the name")
+	flags.BoolVar(&vm.OptionalFields.Network.AssociatePublicIP, "public-ip", false, "associate a public IP")
+	flags.BoolVar(&vm.OptionalFields.SkipOutputs, "skip-outputs", false, "do not generate outputs for the VM")
+	err := parse(flags, args, "workspace", "name", "machine-type", "image", "ssh-key")
+	if err != nil {
+		return err
+	}
+	vm.RequiredFields.Name = *name
+	vm.RequiredFields.MachineType = &terralu.MachineTypeSchema{Name: *machineType}
+	vm.RequiredFields.Image = &terralu.ImageSchema{Name: *image}
+
+	workspace, err := shared.open()
+	if err != nil {
+		return err
+	}
+	manifest, err := workspace.GenerateTerraformVirtualMachineConfig(&vm)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(stdout, manifest)
+	return err
+}
+
+// vmListCommand prints the VMs of the workspace as a table
+func vmListCommand(args []string, stdout io.Writer) error {
+	flags, shared := newFlagSet("vm list")
+	err := parse(flags, args, "workspace")
+	if err != nil {
+		return err
+	}
+	workspace, err := shared.open()
+	if err != nil {
+		return err
+	}
+	vms, err := workspace.ListVirtualMachines()
+	if err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tMACHINE TYPE\tIMAGE\tINSTANCES")
+	for _, vm := range vms {
+		instances := "1"
+		if vm.OptionalFields.Opaque {
+			instances = "-"
+		} else if vm.OptionalFields.Count > 0 {
+			instances = strconv.Itoa(vm.OptionalFields.Count)
+		} else if len(vm.OptionalFields.Instances) > 0 {
+			instances = strconv.Itoa(len(vm.OptionalFields.Instances))
+		}
+		machineType, image := machineTypeAndImage(vm)
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", vm.RequiredFields.Name, orDash(machineType), orDash(image), instances)
+	}
+	return table.Flush()
+}
+
+// machineTypeAndImage returns the machine type and image names of the VM, empty when the workspace
+// sets them with expressions terralu cannot decode
+func machineTypeAndImage(vm *terralu.VirtualMachineInstance) (string, string) {
+	var machineType, image string
+	if vm.RequiredFields.MachineType != nil {
+		machineType = vm.RequiredFields.MachineType.Name
+	}
+	if vm.RequiredFields.Image != nil {
+		image = vm.RequiredFields.Image.Name
+	}
+	return machineType, image
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// vmRemoveCommand removes a VM from the workspace
+func vmRemoveCommand(args []string, stdout io.Writer) error {
+	flags, shared := newFlagSet("vm remove")
+	name := flags.String("name", "", "VM name")
+	err := parse(flags, args, "workspace", "name")
+	if err != nil {
+		return err
+	}
+	workspace, err := shared.open()
+	if err != nil {
+		return err
+	}
+	return workspace.RemoveResource("mgc_virtual_machine_instances", *name)
+}
+
+// renderCommand prints the whole workspace manifest
+func renderCommand(args []string, stdout io.Writer) error {
+	flags, shared := newFlagSet("render")
+	err := parse(flags, args, "workspace")
+	if err != nil {
+		return err
+	}
+	workspace, err := shared.open()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(stdout, workspace.Render())
+	return err
+}
+
+// workspaceOptions converts the layout and credential flags into workspace options
+func workspaceOptions(command, layout string, environmentCredentials bool) ([]terralu.Option, error) {
+	opts := []terralu.Option{}
+	switch layout {
+	case "single":
+	case "multi":
+		opts = append(opts, terralu.WithLayout(terralu.MultiFileLayout))
+	default:
+		return nil, fmt.Errorf("%s: unknown layout %q", command, layout)
+	}
+	if environmentCredentials {
+		opts = append(opts, terralu.WithEnvironmentCredentials())
+	}
+	return opts, nil
+}
+
+// compileCommand creates a workspace from a stack file, or prints the manifest it would contain with -dry-run
+func compileCommand(args []string, stdout io.Writer) error {
+	flags, shared := newFlagSet("compile")
+	file := flags.String("file", "", "stack file, .yaml, .yml or .json")
+	layout := flags.String("layout", "single", "file layout, single or multi")
+	format := flags.String("format", "hcl", "file syntax, hcl or json")
+	environmentCredentials := flags.Bool("env-credentials", false, "read the credentials from TF_VAR_ variables instead of terraform.tfvars")
+	dryRun := flags.Bool("dry-run", false, "print the manifest without creating the workspace")
+	err := parse(flags, args, "file")
+	if err != nil {
+		return err
+	}
+	opts, err := workspaceOptions("compile", *layout, *environmentCredentials)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "hcl":
+	case "json":
+		opts = append(opts, terralu.WithFormat(terralu.JSONFormat))
+	default:
+		return fmt.Errorf("compile: unknown format %q", *format)
+	}
+
+	stack, err := terralu.LoadStack(*file)
+	if err != nil {
+		return err
+	}
+	// Secrets are usually left out of stack files under review
+	for field, variable := range map[*string]string{
+		&stack.Provider.ApiKey:    "MGC_API_KEY",
+		&stack.Provider.KeyID:     "MGC_KEY_ID",
+		&stack.Provider.KeySecret: "MGC_KEY_SECRET",
+	} {
+		if *field == "" {
+			*field = os.Getenv(variable)
+		}
+	}
+
+	if *dryRun {
+		workspace, err := terralu.New(&stack.Provider, append(opts, terralu.InMemory())...)
+		if err != nil {
+			return err
+		}
+		if _, err := workspace.GenerateTerraformGenericProviderConfig(); err != nil {
+			return err
+		}
+		if err := stack.Compile(workspace); err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, workspace.Render())
+		return err
+	}
+
+	if shared.name == "" {
+		return fmt.Errorf("compile: -workspace is required without -dry-run")
+	}
+	manager := terralu.NewWorkspaceManager(shared.root)
+	_, err = manager.CreateFromStack(shared.name, stack, opts...)
+	if err != nil {
+		return err
+	}
+	path, err := manager.Path(shared.name)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, path)
+	return err
+}
+
+// runTerraformCommand runs terraform or tofu in the workspace directory, streaming its output to stdout
+func runTerraformCommand(command string, args []string, stdout io.Writer) error {
+	switch command {
+	case "init", "plan", "apply", "destroy":
+	default:
+		return errUsage
+	}
+	flags, shared := newFlagSet("run " + command)
+	binary := flags.String("binary", "", "terraform or tofu executable, found on the PATH by default")
+	autoApprove := flags.Bool("auto-approve", false, "confirm apply and destroy")
+	err := parse(flags, args, "workspace")
+	if err != nil {
+		return err
+	}
+	if (command == "apply" || command == "destroy") && !*autoApprove {
+		return fmt.Errorf("run %s: -auto-approve is required", command)
+	}
+	// The workspace is not loaded, so this also works for JSON workspaces: terraform reads the credentials
+	// from terraform.tfvars or from the TF_VAR_ variables itself
+	manager := terralu.NewWorkspaceManager(shared.root)
+	if !manager.Exists(shared.name) {
+		return fmt.Errorf("workspace %q not found", shared.name)
+	}
+	dir, err := manager.Path(shared.name)
+	if err != nil {
+		return err
+	}
+	opts := []terralu.ExecutorOption{terralu.WithOutput(stdout)}
+	if *binary != "" {
+		opts = append(opts, terralu.WithBinary(*binary))
+	}
+	executor, err := terralu.NewExecutor(dir, opts...)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "init":
+		return executor.Init(ctx)
+	case "plan":
+		return executor.Plan(ctx)
+	case "apply":
+		return executor.Apply(ctx)
+	case "destroy":
+		return executor.Destroy(ctx)
+	}
+	return errUsage
+}
+
+// schemaTypes are the documents terralu schema can describe, by the name given to -type
+var schemaTypes = map[string]any{
+	"stack":          terralu.Stack{},
+	"provider":       terralu.TerraluProviderInfo{},
+	"vm":             terralu.VirtualMachineInstance{},
+	"network":        terralu.VPCInstance{},
+	"security-group": terralu.SecurityGroupInstance{},
+	"ssh-key":        terralu.SSHKeyInstance{},
+	"database":       terralu.DatabaseInstance{},
+	"bucket":         terralu.BucketInstance{},
+}
+
+// schemaCommand prints the JSON Schema of a stack file or of one of the resource types
+func schemaCommand(args []string, stdout io.Writer) error {
+	flags, _ := newFlagSet("schema")
+	typeName := flags.String("type", "stack", "document to describe")
+	err := parse(flags, args, "type")
+	if err != nil {
+		return err
+	}
+	value, ok := schemaTypes[*typeName]
+	if !ok {
+		return fmt.Errorf("schema: unknown type %q", *typeName)
+	}
+	schema, err := terralu.JSONSchema(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, string(schema))
+	return err
+}
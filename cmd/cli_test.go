@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunCommand tests scripting a workspace through the subcommands
+func TestRunCommand(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("MGC_API_KEY", "access")
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := runCommand(append(args, "-workspaces", root), &out)
+		return out.String(), err
+	}
+
+	if _, err := run("init", "-workspace", "demo", "-region", "br-se1", "-alias", "mgc", "-layout", "multi"); err != nil {
+		t.Fatalf("init error = %v", err)
+	}
+	if _, err := run("init", "-workspace", "demo", "-region", "br-se1", "-alias", "mgc"); err == nil {
+		t.Errorf("expected an error creating the workspace twice")
+	}
+	out, err := run("vm", "add", "-workspace", "demo", "-name", "web", "-machine-type", "BV1-1-10", "-image", "cloud-ubuntu-22.04 LTS", "-ssh-key", "me", "-count", "3")
+	if err != nil {
+		t.Fatalf("vm add error = %v", err)
+	}
+	if !strings.Contains(out, "count    = 3") {
+		t.Errorf("unexpected vm add output:\n%s", out)
+	}
+	if _, err := run("vm", "add", "-workspace", "demo", "-name", "web", "-machine-type", "BV1-1-10", "-image", "cloud-ubuntu-22.04 LTS", "-ssh-key", "me"); err == nil {
+		t.Errorf("expected an error adding the same VM twice")
+	}
+
+	out, err = run("vm", "list", "-workspace", "demo")
+	if err != nil {
+		t.Fatalf("vm list error = %v", err)
+	}
+	if fields := strings.Fields(strings.Split(out, "\n")[1]); len(fields) < 3 || fields[0] != "web" || fields[len(fields)-1] != "3" {
+		t.Errorf("unexpected vm list output:\n%s", out)
+	}
+
+	out, err = run("render", "-workspace", "demo")
+	if err != nil || !strings.Contains(out, `provider "mgc"`) || !strings.Contains(out, `resource "mgc_virtual_machine_instances" "web"`) {
+		t.Errorf("unexpected render output %v:\n%s", err, out)
+	}
+
+	if _, err := run("vm", "remove", "-workspace", "demo", "-name", "web"); err != nil {
+		t.Fatalf("vm remove error = %v", err)
+	}
+	out, _ = run("render", "-workspace", "demo")
+	if strings.Contains(out, "mgc_virtual_machine_instances") {
+		t.Errorf("expected the VM to be removed:\n%s", out)
+	}
+
+	handWritten := "resource \"mgc_virtual_machine_instances\" \"custom\" {\n  name         = \"custom\"\n  machine_type = local.size\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "demo", "main.tf"), []byte(handWritten), 0644); err != nil {
+		t.Fatalf("error writing main.tf: %v", err)
+	}
+	out, err = run("vm", "list", "-workspace", "demo")
+	if fields := strings.Fields(strings.Split(out, "\n")[1]); err != nil || len(fields) != 4 || fields[0] != "custom" || fields[1] != "-" {
+		t.Errorf("unexpected vm list output for a hand-written VM %v:\n%s", err, out)
+	}
+
+	out, err = run("compile", "-dry-run", "-file", filepath.Join("..", "examples", "stack.yaml"))
+	if err != nil || !strings.Contains(out, `count    = 3`) {
+		t.Errorf("unexpected compile output %v:\n%s", err, out)
+	}
+	out, err = run("compile", "-dry-run", "-format", "json", "-file", filepath.Join("..", "examples", "stack.yaml"))
+	if err != nil || !strings.Contains(out, `"count": 3`) {
+		t.Errorf("unexpected JSON compile output %v:\n%s", err, out)
+	}
+	if _, err := run("compile", "-workspace", "stack", "-file", filepath.Join("..", "examples", "stack.yaml")); err != nil {
+		t.Fatalf("compile error = %v", err)
+	}
+	out, _ = run("vm", "list", "-workspace", "stack")
+	if !strings.Contains(out, "worker") {
+		t.Errorf("expected the compiled VMs to be listed:\n%s", out)
+	}
+
+	out, err = run("schema")
+	if err != nil || !strings.Contains(out, `"$ref": "#/$defs/Stack"`) {
+		t.Errorf("unexpected schema output %v:\n%s", err, out)
+	}
+
+	terraform := filepath.Join(t.TempDir(), "terraform")
+	if err := os.WriteFile(terraform, []byte("#!/bin/sh\necho \"$* $(cat terraform.tfvars)\"\n"), 0755); err != nil {
+		t.Fatalf("error writing the fake terraform: %v", err)
+	}
+	out, err = run("run", "plan", "-workspace", "demo", "-binary", terraform)
+	if err != nil || out != "plan -input=false api_key = \"access\"\n" {
+		t.Errorf("unexpected run output %v: %q", err, out)
+	}
+
+	if _, err := run("compile", "-workspace", "json", "-format", "json", "-file", filepath.Join("..", "examples", "stack.yaml")); err != nil {
+		t.Fatalf("compile error = %v", err)
+	}
+	if out, err := run("run", "init", "-workspace", "json", "-binary", terraform); err != nil || !strings.HasPrefix(out, "init") {
+		t.Errorf("unexpected run output for a JSON workspace %v: %q", err, out)
+	}
+
+	for _, args := range [][]string{
+		{"run", "apply", "-workspace", "demo", "-binary", terraform},
+		{"run", "refresh", "-workspace", "demo"},
+		{"run", "plan", "-workspace", "missing", "-binary", terraform},
+		{"run"},
+		{"schema", "-type", "cluster"},
+		{"compile", "-dry-run", "-format", "yaml", "-file", filepath.Join("..", "examples", "stack.yaml")},
+		{"compile", "-file", filepath.Join("..", "examples", "stack.yaml")},
+		{"vm"},
+		{"vm", "stop"},
+		{"deploy"},
+		{"render"},
+		{"vm", "add", "-workspace", "demo", "-name", "web"},
+		{"init", "-workspace", "other", "-region", "br-se1", "-alias", "mgc", "-layout", "nested"},
+		{"render", "-workspace", "missing"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -1,43 +1,222 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joaogabriel01/terralu"
 	"github.com/rivo/tview"
 )
 
-type TerraluProviderInfo struct {
-	Alias     string `json:"alias" validate:"required"`
-	Region    string `json:"region" validate:"required"`
-	ApiKey    string `json:"api_key" validate:"required"`
-	KeyID     string `json:"key_id"`
-	KeySecret string `json:"key_secret"`
-}
-
 type AppData struct {
 	terralu.TerraluProviderInfo
 	Template string
 }
 
 type VMData struct {
+	Name         string
+	MachineType  string
+	Image        string
+	SSHKeyName   string
+	UserDataPath string
+	SkipOutputs  bool
+	Count        string
+}
+
+type DatabaseData struct {
+	Name                string
+	EngineVersion       string
+	InstanceType        string
+	VolumeSize          string
+	User                string
+	Password            string
+	BackupRetentionDays string
+	SkipOutputs         bool
+}
+
+type BlockStorageData struct {
+	Name     string
+	Size     string
+	Type     string
+	AttachTo string
+}
+
+type BucketData struct {
+	Name             string
+	EnableVersioning bool
+	ACL              string
+	PreventDestroy   bool
+	SkipOutputs      bool
+}
+
+type KubernetesData struct {
 	Name        string
-	MachineType string
-	Image       string
-	SSHKeyName  string
+	Version     string
+	VPC         string
+	PoolName    string
+	Flavor      string
+	Replicas    string
+	AutoScale   bool
+	MinReplicas string
+	MaxReplicas string
+	Labels      string
+	Taints      string
+}
+
+type SSHKeyData struct {
+	Name      string
+	PublicKey string
+}
+
+type ContainerData struct {
+	VMData
+	Mode        string
+	ComposePath string
+	Environment string
 }
 
 var app *tview.Application
 var pages *tview.Pages
 var data *AppData = &AppData{}
 var terraluProvider terralu.Terralu
+var workspaceManager *terralu.WorkspaceManager
+
+// workspaceDir is the directory of the open workspace, where terraform runs
+var workspaceDir string
+
+// sshKeys holds the SSH keys generated during the session, so VMs can reference them by address
+var sshKeys = map[string]*terralu.SSHKeyInstance{}
 
 func main() {
+	// A subcommand runs without the interactive interface, so terralu can be scripted
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		err := runCommand(os.Args[1:], os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "terralu:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	defaultRoot, err := terralu.DefaultWorkspaceRoot()
+	if err != nil {
+		panic(err)
+	}
+	root := flag.String("workspaces", defaultRoot, "directory where workspaces are kept")
+	flag.Parse()
+	workspaceManager = terralu.NewWorkspaceManager(*root)
 
 	app = tview.NewApplication()
 	pages = tview.NewPages()
 	fmt.Println("Terralu CLI")
+
+	workspaces()
+
+	if err := app.SetRoot(pages, true).EnableMouse(true).EnablePaste(true).Run(); err != nil {
+		panic(err)
+	}
+}
+
+func workspaces() {
+	names, err := workspaceManager.List()
+	if err != nil {
+		panic(err)
+	}
+
+	list := tview.NewList()
+	for _, name := range names {
+		name := name
+		list.AddItem(name, "", 0, func() {
+			chooseWorkspaceAction(name)
+		})
+	}
+	list.AddItem("New workspace", "", 'n', func() {
+		newWorkspace()
+	})
+	list.AddItem("Quit", "", 'q', func() {
+		app.Stop()
+	})
+
+	list.SetBorder(true).SetTitle("Workspaces in " + workspaceManager.Root()).SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("workspaces", list, true, true)
+	pages.SwitchToPage("workspaces")
+}
+
+func chooseWorkspaceAction(name string) {
+	modal := tview.NewModal().
+		SetText("Workspace " + name).
+		AddButtons([]string{"Open", "Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Open":
+				workspace, err := workspaceManager.Open(name)
+				if err != nil {
+					// JSON workspaces and broken files cannot be opened, but the rest of the list still can
+					showError(err, "workspaces")
+					return
+				}
+				terraluProvider = workspace
+				workspaceDir, _ = workspaceManager.Path(name)
+				chooseService()
+			case "Delete":
+				confirmDeleteWorkspace(name)
+			default:
+				pages.SwitchToPage("workspaces")
+			}
+		})
+
+	pages.AddPage("workspaceAction", modal, true, true)
+	pages.SwitchToPage("workspaceAction")
+}
+
+// confirmDeleteWorkspace asks before deleting a workspace, since its directory may hold the Terraform state
+func confirmDeleteWorkspace(name string) {
+	modal := tview.NewModal().
+		SetText("Delete workspace " + name + " and all of its files?").
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel != "Delete" {
+				pages.SwitchToPage("workspaces")
+				return
+			}
+			err := workspaceManager.Delete(name)
+			if err != nil {
+				showError(err, "workspaces")
+				return
+			}
+			workspaces()
+		})
+
+	pages.AddPage("confirmDelete", modal, true, true)
+	pages.SwitchToPage("confirmDelete")
+}
+
+func showError(err error, back string) {
+	modal := tview.NewModal().
+		SetText(err.Error()).
+		AddButtons([]string{"Back"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.SwitchToPage(back)
+		})
+
+	pages.AddPage("error", modal, true, true)
+	pages.SwitchToPage("error")
+}
+
+func newWorkspace() {
+	var name string
+	layout := terralu.SingleFileLayout
+	environmentCredentials := false
 	form := tview.NewForm().
+		AddInputField("Workspace Name", "", 50, nil, func(text string) {
+			name = text
+		}).
 		AddInputField("Api Key", "", 50, nil, func(text string) {
 			data.ApiKey = text
 		}).
@@ -56,26 +235,55 @@ func main() {
 		AddDropDown("Template", []string{"Native", "Customized"}, 0, func(option string, optionIndex int) {
 			data.Template = option
 		}).
+		AddCheckbox("Split into files", false, func(checked bool) {
+			if checked {
+				layout = terralu.MultiFileLayout
+			} else {
+				layout = terralu.SingleFileLayout
+			}
+		}).
+		AddCheckbox("Credentials from environment", false, func(checked bool) {
+			environmentCredentials = checked
+		}).
 		AddButton("Save", func() {
-			terraluProvider = terralu.NewTerralu(&data.TerraluProviderInfo)
-			_, err := terraluProvider.GenerateTerraformGenericProviderConfig()
+			opts := []terralu.Option{terralu.WithLayout(layout)}
+			if environmentCredentials {
+				opts = append(opts, terralu.WithEnvironmentCredentials())
+			}
+			workspace, err := workspaceManager.Create(name, &data.TerraluProviderInfo, opts...)
 			if err != nil {
-				panic("Error generating provider config: " + err.Error())
+				showError(fmt.Errorf("error creating the workspace: %w", err), "main")
+				return
 			}
+			terraluProvider = workspace
+			workspaceDir, _ = workspaceManager.Path(name)
 
+			if environmentCredentials {
+				showEnvironmentInstructions()
+				return
+			}
 			chooseService()
 		}).
-		AddButton("Quit", func() {
-			app.Stop()
+		AddButton("Back", func() {
+			pages.SwitchToPage("workspaces")
 		})
 
 	form.SetBorder(true).SetTitle("Enter some data").SetTitleAlign(tview.AlignLeft)
 
 	pages.AddPage("main", form, true, true)
+	pages.SwitchToPage("main")
+}
 
-	if err := app.SetRoot(pages, true).EnableMouse(true).EnablePaste(true).Run(); err != nil {
-		panic(err)
-	}
+func showEnvironmentInstructions() {
+	modal := tview.NewModal().
+		SetText("Export the credentials before running Terraform:\n\n" + terralu.EnvironmentInstructions(&data.TerraluProviderInfo)).
+		AddButtons([]string{"Continue"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			chooseService()
+		})
+
+	pages.AddPage("environmentInstructions", modal, true, true)
+	pages.SwitchToPage("environmentInstructions")
 }
 
 func chooseService() {
@@ -83,17 +291,32 @@ func chooseService() {
 		AddButton("VMs", func() {
 			vms()
 		}).
+		AddButton("Manage VMs", func() {
+			manageVMs()
+		}).
+		AddButton("SSH Keys", func() {
+			sshKey()
+		}).
 		AddButton("MySQL", func() {
-			showNotImplemented()
+			databases()
 		}).
 		AddButton("BlockStorage", func() {
-			showNotImplemented()
+			blockStorage()
 		}).
 		AddButton("ObjectStorage", func() {
-			showNotImplemented()
+			objectStorage()
+		}).
+		AddButton("Docker", func() {
+			containers()
+		}).
+		AddButton("Kubernetes", func() {
+			kubernetes()
+		}).
+		AddButton("Terraform", func() {
+			showTerraform("Workspace", terraluProvider.Render())
 		}).
 		AddButton("Back", func() {
-			pages.SwitchToPage("main")
+			workspaces()
 		})
 
 	form.SetBorder(true).SetTitle("Choose the service").SetTitleAlign(tview.AlignLeft)
@@ -118,6 +341,15 @@ func vms() {
 		AddInputField("SSH Key Name", "", 50, nil, func(text string) {
 			vmData.SSHKeyName = text
 		}).
+		AddInputField("User Data (cloud-config file)", "", 50, nil, func(text string) {
+			vmData.UserDataPath = text
+		}).
+		AddInputField("Count (identical VMs)", "", 10, tview.InputFieldInteger, func(text string) {
+			vmData.Count = text
+		}).
+		AddCheckbox("Skip Outputs", false, func(checked bool) {
+			vmData.SkipOutputs = checked
+		}).
 		AddButton("Create", func() {
 			showProvider(&vmData)
 		}).
@@ -131,6 +363,99 @@ func vms() {
 	pages.SwitchToPage("vms")
 }
 
+func manageVMs() {
+	machines, err := terraluProvider.ListVirtualMachines()
+	if err != nil {
+		panic(err)
+	}
+
+	list := tview.NewList()
+	for _, machine := range machines {
+		machine := machine
+		machineType, _ := machineTypeAndImage(machine)
+		if machine.OptionalFields.Opaque {
+			machineType = "hand-written, can only be deleted"
+		}
+		list.AddItem(machine.RequiredFields.Name, machineType, 0, func() {
+			editVM(machine)
+		})
+	}
+	list.AddItem("Back", "", 'b', func() {
+		pages.SwitchToPage("chooseService")
+	})
+
+	list.SetBorder(true).SetTitle("Virtual Machines").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("manageVMs", list, true, true)
+	pages.SwitchToPage("manageVMs")
+}
+
+func editVM(machine *terralu.VirtualMachineInstance) {
+	originalName := machine.RequiredFields.Name
+	machineType, image := machineTypeAndImage(machine)
+	vmData := VMData{
+		Name:        machine.RequiredFields.Name,
+		MachineType: machineType,
+		Image:       image,
+		SSHKeyName:  machine.RequiredFields.SSHKeyName,
+	}
+
+	form := tview.NewForm().
+		AddInputField("Name", vmData.Name, 50, nil, func(text string) {
+			vmData.Name = text
+		}).
+		AddInputField("Machine Type", vmData.MachineType, 50, nil, func(text string) {
+			vmData.MachineType = text
+		}).
+		AddInputField("Image", vmData.Image, 50, nil, func(text string) {
+			vmData.Image = text
+		}).
+		AddCheckbox("Skip Outputs", machine.OptionalFields.SkipOutputs, func(checked bool) {
+			machine.OptionalFields.SkipOutputs = checked
+		}).
+		AddButton("Save", func() {
+			machine.RequiredFields.Name = vmData.Name
+			machine.RequiredFields.MachineType = &terralu.MachineTypeSchema{Name: vmData.MachineType}
+			machine.RequiredFields.Image = &terralu.ImageSchema{Name: vmData.Image}
+			response, err := terraluProvider.UpdateVirtualMachine(originalName, machine)
+			if err != nil {
+				showError(err, "editVM")
+				return
+			}
+			showText("VM Data", response)
+		}).
+		AddButton("Delete", func() {
+			// Removing a VM other resources are attached to is refused, so the error is shown rather than fatal
+			err := terraluProvider.RemoveResource("mgc_virtual_machine_instances", originalName)
+			if err != nil {
+				showError(err, "editVM")
+				return
+			}
+			manageVMs()
+		}).
+		AddButton("Back", func() {
+			manageVMs()
+		})
+
+	form.SetBorder(true).SetTitle("Edit VM").SetTitleAlign(tview.AlignLeft)
+	if machine.OptionalFields.Opaque {
+		// UpdateVirtualMachine refuses VMs set with expressions terralu cannot decode
+		form.RemoveButton(0)
+		form.SetTitle("Edit VM (hand-written, can only be deleted)")
+	}
+
+	pages.AddPage("editVM", form, true, true)
+	pages.SwitchToPage("editVM")
+}
+
+func showText(title, content string) {
+	text := tview.NewTextView().
+		SetText(content)
+
+	text.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("text", text, true, true)
+	pages.SwitchToPage("text")
+}
+
 func showProvider(vmData *VMData) {
 	required := terralu.VirtualMachineRequiredFields{
 		Name:        vmData.Name,
@@ -138,29 +463,520 @@ func showProvider(vmData *VMData) {
 		Image:       &terralu.ImageSchema{Name: vmData.Image},
 		SSHKeyName:  vmData.SSHKeyName,
 	}
+	if key, ok := sshKeys[vmData.SSHKeyName]; ok {
+		required.SSHKey = key.Reference()
+	}
+	count, _ := strconv.Atoi(vmData.Count)
 	machine := terralu.VirtualMachineInstance{
 		RequiredFields: required,
+		OptionalFields: terralu.VirtualMachineOptionalFields{
+			UserDataPath: vmData.UserDataPath,
+			SkipOutputs:  vmData.SkipOutputs,
+			Count:        count,
+		},
 	}
 	response, err := terraluProvider.GenerateTerraformVirtualMachineConfig(&machine)
 	if err != nil {
-		panic(err)
+		showError(err, "vms")
+		return
+	}
+	showTerraform("VM Data", response)
+}
+
+// escapeWriter escapes the text written to it, so resource addresses such as web[0] are not read as style tags.
+// It must wrap the ANSIWriter, not the other way around, or the tags made from the colour codes are escaped too
+type escapeWriter struct {
+	io.Writer
+}
+
+func (w escapeWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(w.Writer, tview.Escape(string(p)))
+	return len(p), err
+}
+
+// showTerraform shows the generated manifest next to a pane where terraform runs in the workspace
+func showTerraform(title, manifest string) {
+	text := tview.NewTextView().
+		SetText(manifest)
+	text.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+
+	output := tview.NewTextView().
+		SetDynamicColors(true).
+		SetChangedFunc(func() {
+			app.Draw()
+		})
+	output.SetBorder(true).SetTitle("Terraform").SetTitleAlign(tview.AlignLeft)
+
+	// running is only read and written on the event loop
+	running := false
+	run := func(command string, action func(*terralu.Executor, context.Context) error) {
+		if running {
+			return
+		}
+		executor, err := terralu.NewExecutor(workspaceDir,
+			terralu.WithOutput(escapeWriter{tview.ANSIWriter(output)}),
+			terralu.WithCredentials(terraluProvider.GetTerraluProviderInfo()))
+		if err != nil {
+			fmt.Fprintf(output, "[red]%s[-]\n", tview.Escape(err.Error()))
+			return
+		}
+		running = true
+		fmt.Fprintf(output, "[yellow]$ %s %s[-]\n", filepath.Base(executor.Binary()), command)
+		go func() {
+			err := action(executor, context.Background())
+			app.QueueUpdateDraw(func() {
+				running = false
+				if err != nil {
+					fmt.Fprintf(output, "[red]%s[-]\n\n", tview.Escape(err.Error()))
+				} else {
+					fmt.Fprintf(output, "[green]%s finished with exit status 0[-]\n\n", command)
+				}
+			})
+		}()
+	}
+	// apply and destroy run without terraform asking, so they are confirmed here
+	confirm := func(command string, action func(*terralu.Executor, context.Context) error) {
+		modal := tview.NewModal().
+			SetText("Run terraform " + command + " in " + workspaceDir + "?").
+			AddButtons([]string{"Run", "Cancel"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				pages.SwitchToPage("terraform")
+				if buttonLabel == "Run" {
+					run(command, action)
+				}
+			})
+		pages.AddPage("confirmTerraform", modal, true, true)
+		pages.SwitchToPage("confirmTerraform")
+	}
+
+	form := tview.NewForm().
+		AddButton("Init", func() {
+			run("init", (*terralu.Executor).Init)
+		}).
+		AddButton("Plan", func() {
+			run("plan", (*terralu.Executor).Plan)
+		}).
+		AddButton("Apply", func() {
+			confirm("apply", (*terralu.Executor).Apply)
+		}).
+		AddButton("Destroy", func() {
+			confirm("destroy", (*terralu.Executor).Destroy)
+		}).
+		AddButton("Back", func() {
+			chooseService()
+		})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(text, 0, 1, false).
+			AddItem(output, 0, 1, false), 0, 1, false).
+		AddItem(form, 3, 0, true)
+	pages.AddPage("terraform", layout, true, true)
+	pages.SwitchToPage("terraform")
+}
+
+func sshKey() {
+	var keyData SSHKeyData
+
+	form := tview.NewForm().
+		AddInputField("Name", "", 50, nil, func(text string) {
+			keyData.Name = text
+		}).
+		AddTextArea("Public Key (text or file path)", "", 70, 3, 0, func(text string) {
+			keyData.PublicKey = text
+		}).
+		AddButton("Create", func() {
+			showSSHKey(&keyData)
+		}).
+		AddButton("Back", func() {
+			pages.SwitchToPage("chooseService")
+		})
+
+	form.SetBorder(true).SetTitle("Configure SSH Key").SetTitleAlign(tview.AlignLeft)
+
+	pages.AddPage("sshKey", form, true, true)
+	pages.SwitchToPage("sshKey")
+}
+
+func showSSHKey(keyData *SSHKeyData) {
+	key := terralu.SSHKeyInstance{Name: keyData.Name}
+	publicKey := strings.TrimSpace(keyData.PublicKey)
+	if _, err := os.Stat(publicKey); err == nil {
+		key.PublicKeyPath = publicKey
+	} else {
+		key.PublicKey = publicKey
 	}
+	response, err := terraluProvider.GenerateTerraformSSHKeyConfig(&key)
+	if err != nil {
+		showError(err, "sshKey")
+		return
+	}
+	sshKeys[key.Name] = &key
 	text := tview.NewTextView().
 		SetText(response)
 
-	text.SetBorder(true).SetTitle("VM Data").SetTitleAlign(tview.AlignLeft)
-	pages.AddPage("vmData", text, true, true)
-	pages.SwitchToPage("vmData")
+	text.SetBorder(true).SetTitle("SSH Key Data").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("sshKeyData", text, true, true)
+	pages.SwitchToPage("sshKeyData")
 }
 
-func showNotImplemented() {
-	modal := tview.NewModal().
-		SetText("This functionality is not implemented yet.").
-		AddButtons([]string{"OK"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+func databases() {
+	var dbData DatabaseData
+
+	form := tview.NewForm().
+		AddInputField("Name", "", 50, nil, func(text string) {
+			dbData.Name = text
+		}).
+		AddDropDown("Engine Version", []string{"8.0", "8.4"}, 0, func(option string, optionIndex int) {
+			dbData.EngineVersion = option
+		}).
+		AddInputField("Instance Type", "", 50, nil, func(text string) {
+			dbData.InstanceType = text
+		}).
+		AddInputField("Volume Size (GB)", "", 10, tview.InputFieldInteger, func(text string) {
+			dbData.VolumeSize = text
+		}).
+		AddInputField("User", "", 50, nil, func(text string) {
+			dbData.User = text
+		}).
+		AddPasswordField("Password", "", 50, '*', func(text string) {
+			dbData.Password = text
+		}).
+		AddInputField("Backup Retention (days)", "", 10, tview.InputFieldInteger, func(text string) {
+			dbData.BackupRetentionDays = text
+		}).
+		AddCheckbox("Skip Outputs", false, func(checked bool) {
+			dbData.SkipOutputs = checked
+		}).
+		AddButton("Create", func() {
+			showDatabase(&dbData)
+		}).
+		AddButton("Back", func() {
+			pages.SwitchToPage("chooseService")
+		})
+
+	form.SetBorder(true).SetTitle("Configure MySQL").SetTitleAlign(tview.AlignLeft)
+
+	pages.AddPage("databases", form, true, true)
+	pages.SwitchToPage("databases")
+}
+
+func showDatabase(dbData *DatabaseData) {
+	volumeSize, _ := strconv.Atoi(dbData.VolumeSize)
+	backupRetentionDays, _ := strconv.Atoi(dbData.BackupRetentionDays)
+	db := terralu.DatabaseInstance{
+		Name:                dbData.Name,
+		EngineVersion:       dbData.EngineVersion,
+		InstanceType:        dbData.InstanceType,
+		VolumeSize:          volumeSize,
+		User:                dbData.User,
+		Password:            dbData.Password,
+		BackupRetentionDays: backupRetentionDays,
+		SkipOutputs:         dbData.SkipOutputs,
+	}
+	response, err := terraluProvider.GenerateTerraformDatabaseConfig(&db)
+	if err != nil {
+		showError(err, "databases")
+		return
+	}
+	text := tview.NewTextView().
+		SetText(response)
+
+	text.SetBorder(true).SetTitle("MySQL Data").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("databaseData", text, true, true)
+	pages.SwitchToPage("databaseData")
+}
+
+func blockStorage() {
+	var volumeData BlockStorageData
+
+	form := tview.NewForm().
+		AddInputField("Name", "", 50, nil, func(text string) {
+			volumeData.Name = text
+		}).
+		AddInputField("Size (GB)", "", 10, tview.InputFieldInteger, func(text string) {
+			volumeData.Size = text
+		}).
+		AddDropDown("Type", []string{"cloud_nvme1k", "cloud_nvme5k", "cloud_nvme10k"}, 0, func(option string, optionIndex int) {
+			volumeData.Type = option
+		}).
+		AddInputField("Attach to VM (name)", "", 50, nil, func(text string) {
+			volumeData.AttachTo = text
+		}).
+		AddButton("Create", func() {
+			showBlockStorage(&volumeData)
+		}).
+		AddButton("Back", func() {
+			pages.SwitchToPage("chooseService")
+		})
+
+	form.SetBorder(true).SetTitle("Configure Block Storage").SetTitleAlign(tview.AlignLeft)
+
+	pages.AddPage("blockStorage", form, true, true)
+	pages.SwitchToPage("blockStorage")
+}
+
+func showBlockStorage(volumeData *BlockStorageData) {
+	size, _ := strconv.Atoi(volumeData.Size)
+	volume := terralu.BlockStorageInstance{
+		Name: volumeData.Name,
+		Size: size,
+		Type: volumeData.Type,
+	}
+	if volumeData.AttachTo != "" {
+		vm := terralu.VirtualMachineInstance{
+			RequiredFields: terralu.VirtualMachineRequiredFields{Name: volumeData.AttachTo},
+		}
+		volume.AttachTo = vm.Reference()
+	}
+	response, err := terraluProvider.GenerateTerraformBlockStorageConfig(&volume)
+	if err != nil {
+		showError(err, "blockStorage")
+		return
+	}
+	text := tview.NewTextView().
+		SetText(response)
+
+	text.SetBorder(true).SetTitle("Block Storage Data").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("blockStorageData", text, true, true)
+	pages.SwitchToPage("blockStorageData")
+}
+
+func objectStorage() {
+	var bucketData BucketData
+
+	form := tview.NewForm().
+		AddInputField("Bucket Name", "", 50, nil, func(text string) {
+			bucketData.Name = text
+		}).
+		AddCheckbox("Enable Versioning", false, func(checked bool) {
+			bucketData.EnableVersioning = checked
+		}).
+		AddDropDown("ACL", []string{"private", "public-read", "public-read-write", "authenticated-read"}, 0, func(option string, optionIndex int) {
+			bucketData.ACL = option
+		}).
+		AddCheckbox("Prevent Destroy", false, func(checked bool) {
+			bucketData.PreventDestroy = checked
+		}).
+		AddCheckbox("Skip Outputs", false, func(checked bool) {
+			bucketData.SkipOutputs = checked
+		}).
+		AddButton("Create", func() {
+			showBucket(&bucketData)
+		}).
+		AddButton("Back", func() {
+			pages.SwitchToPage("chooseService")
+		})
+
+	form.SetBorder(true).SetTitle("Configure Object Storage").SetTitleAlign(tview.AlignLeft)
+
+	pages.AddPage("objectStorage", form, true, true)
+	pages.SwitchToPage("objectStorage")
+}
+
+func showBucket(bucketData *BucketData) {
+	bucket := terralu.BucketInstance{
+		Name:             bucketData.Name,
+		EnableVersioning: bucketData.EnableVersioning,
+		ACL:              bucketData.ACL,
+		PreventDestroy:   bucketData.PreventDestroy,
+		SkipOutputs:      bucketData.SkipOutputs,
+	}
+	response, err := terraluProvider.GenerateTerraformBucketConfig(&bucket)
+	if err != nil {
+		showError(err, "objectStorage")
+		return
+	}
+	text := tview.NewTextView().
+		SetText(response)
+
+	text.SetBorder(true).SetTitle("Bucket Data").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("bucketData", text, true, true)
+	pages.SwitchToPage("bucketData")
+}
+
+func containers() {
+	var containerData ContainerData
+	modes := append(terralu.ContainerPresetNames(), "custom")
+
+	form := tview.NewForm().
+		AddInputField("Name", "", 50, nil, func(text string) {
+			containerData.Name = text
+		}).
+		AddInputField("Machine Type", "", 50, nil, func(text string) {
+			containerData.MachineType = text
+		}).
+		AddInputField("Image", "", 50, nil, func(text string) {
+			containerData.Image = text
+		}).
+		AddInputField("SSH Key Name", "", 50, nil, func(text string) {
+			containerData.SSHKeyName = text
+		}).
+		AddDropDown("Stack", modes, 0, func(option string, optionIndex int) {
+			containerData.Mode = option
+		}).
+		AddInputField("docker-compose file (custom)", "", 50, nil, func(text string) {
+			containerData.ComposePath = text
+		}).
+		AddInputField("Environment (k=v,...)", "", 50, nil, func(text string) {
+			containerData.Environment = text
+		}).
+		AddButton("Create", func() {
+			showContainer(&containerData)
+		}).
+		AddButton("Back", func() {
 			pages.SwitchToPage("chooseService")
 		})
 
-	pages.AddPage("notImplemented", modal, true, true)
-	pages.SwitchToPage("notImplemented")
+	form.SetBorder(true).SetTitle("Configure Docker").SetTitleAlign(tview.AlignLeft)
+
+	pages.AddPage("containers", form, true, true)
+	pages.SwitchToPage("containers")
+}
+
+func showContainer(containerData *ContainerData) {
+	required := terralu.VirtualMachineRequiredFields{
+		Name:        containerData.Name,
+		MachineType: &terralu.MachineTypeSchema{Name: containerData.MachineType},
+		Image:       &terralu.ImageSchema{Name: containerData.Image},
+		SSHKeyName:  containerData.SSHKeyName,
+	}
+	if key, ok := sshKeys[containerData.SSHKeyName]; ok {
+		required.SSHKey = key.Reference()
+	}
+	container := terralu.ContainerInstance{
+		Host:        terralu.VirtualMachineInstance{RequiredFields: required},
+		Environment: parseLabels(containerData.Environment),
+	}
+	if containerData.Mode == "custom" {
+		container.ComposePath = containerData.ComposePath
+	} else {
+		container.Preset = containerData.Mode
+	}
+	response, err := terraluProvider.GenerateTerraformContainerConfig(&container)
+	if err != nil {
+		showError(err, "containers")
+		return
+	}
+	text := tview.NewTextView().
+		SetText(response)
+
+	text.SetBorder(true).SetTitle("Docker Data").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("containerData", text, true, true)
+	pages.SwitchToPage("containerData")
+}
+
+func kubernetes() {
+	var k8sData KubernetesData
+
+	form := tview.NewForm().
+		AddInputField("Cluster Name", "", 50, nil, func(text string) {
+			k8sData.Name = text
+		}).
+		AddInputField("Version", "", 20, nil, func(text string) {
+			k8sData.Version = text
+		}).
+		AddInputField("VPC (name)", "", 50, nil, func(text string) {
+			k8sData.VPC = text
+		}).
+		AddInputField("Node Pool Name", "", 50, nil, func(text string) {
+			k8sData.PoolName = text
+		}).
+		AddInputField("Flavor", "", 50, nil, func(text string) {
+			k8sData.Flavor = text
+		}).
+		AddInputField("Replicas", "", 10, tview.InputFieldInteger, func(text string) {
+			k8sData.Replicas = text
+		}).
+		AddCheckbox("Autoscaling", false, func(checked bool) {
+			k8sData.AutoScale = checked
+		}).
+		AddInputField("Min Replicas", "", 10, tview.InputFieldInteger, func(text string) {
+			k8sData.MinReplicas = text
+		}).
+		AddInputField("Max Replicas", "", 10, tview.InputFieldInteger, func(text string) {
+			k8sData.MaxReplicas = text
+		}).
+		AddInputField("Labels (k=v,...)", "", 50, nil, func(text string) {
+			k8sData.Labels = text
+		}).
+		AddInputField("Taints (k=v:Effect,...)", "", 50, nil, func(text string) {
+			k8sData.Taints = text
+		}).
+		AddButton("Create", func() {
+			showKubernetes(&k8sData)
+		}).
+		AddButton("Back", func() {
+			pages.SwitchToPage("chooseService")
+		})
+
+	form.SetBorder(true).SetTitle("Configure Kubernetes").SetTitleAlign(tview.AlignLeft)
+
+	pages.AddPage("kubernetes", form, true, true)
+	pages.SwitchToPage("kubernetes")
+}
+
+func showKubernetes(k8sData *KubernetesData) {
+	replicas, _ := strconv.Atoi(k8sData.Replicas)
+	minReplicas, _ := strconv.Atoi(k8sData.MinReplicas)
+	maxReplicas, _ := strconv.Atoi(k8sData.MaxReplicas)
+	cluster := terralu.KubernetesClusterInstance{
+		Name:    k8sData.Name,
+		Version: k8sData.Version,
+		NodePools: []terralu.NodePoolSchema{
+			{
+				Name:        k8sData.PoolName,
+				Flavor:      k8sData.Flavor,
+				Replicas:    replicas,
+				AutoScale:   k8sData.AutoScale,
+				MinReplicas: minReplicas,
+				MaxReplicas: maxReplicas,
+				Labels:      parseLabels(k8sData.Labels),
+				Taints:      parseTaints(k8sData.Taints),
+			},
+		},
+	}
+	if k8sData.VPC != "" {
+		vpc := terralu.VPCInstance{Name: k8sData.VPC}
+		cluster.VPC = vpc.Reference()
+	}
+	response, err := terraluProvider.GenerateTerraformKubernetesConfig(&cluster)
+	if err != nil {
+		showError(err, "kubernetes")
+		return
+	}
+	text := tview.NewTextView().
+		SetText(response)
+
+	text.SetBorder(true).SetTitle("Kubernetes Data").SetTitleAlign(tview.AlignLeft)
+	pages.AddPage("kubernetesData", text, true, true)
+	pages.SwitchToPage("kubernetesData")
+}
+
+// parseLabels parses a comma separated list of key=value pairs
+func parseLabels(text string) map[string]string {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(text, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || key == "" {
+			continue
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// parseTaints parses a comma separated list of key=value:Effect taints
+func parseTaints(text string) []terralu.TaintSchema {
+	var taints []terralu.TaintSchema
+	for _, entry := range strings.Split(text, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		keyValue, effect, _ := strings.Cut(entry, ":")
+		key, value, _ := strings.Cut(keyValue, "=")
+		taints = append(taints, terralu.TaintSchema{Key: key, Value: value, Effect: effect})
+	}
+	return taints
 }
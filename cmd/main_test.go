@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+// TestEscapeWriter tests that terraform output keeps its colours and its brackets in the output pane
+func TestEscapeWriter(t *testing.T) {
+	view := tview.NewTextView().SetDynamicColors(true)
+	writer := escapeWriter{tview.ANSIWriter(view)}
+	if _, err := writer.Write([]byte("\x1b[31mError:\x1b[0m mgc_virtual_machine_instances.web[0]\n")); err != nil {
+		t.Fatalf("Write error = %v", err)
+	}
+	if got, want := view.GetText(true), "Error: mgc_virtual_machine_instances.web[0]\n"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if got, want := view.GetText(false), "[maroon:]Error:[-:-:-] mgc_virtual_machine_instances.web[0[]\n"; got != want {
+		t.Errorf("tagged text = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+package terralu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// ContainerPresets is the catalog of pre-configured docker-compose stacks
+var ContainerPresets = map[string]string{
+	"nginx": `services:
+  nginx:
+    image: nginx:stable
+    restart: unless-stopped
+    ports:
+      - "80:80"
+`,
+	"postgres": `services:
+  postgres:
+    image: postgres:16
+    restart: unless-stopped
+    environment:
+      POSTGRES_USER: ${POSTGRES_USER:-postgres}
+      POSTGRES_PASSWORD: ${POSTGRES_PASSWORD:?POSTGRES_PASSWORD is required}
+      POSTGRES_DB: ${POSTGRES_DB:-postgres}
+    ports:
+      - "5432:5432"
+    volumes:
+      - postgres-data:/var/lib/postgresql/data
+volumes:
+  postgres-data:
+`,
+	"redis": `services:
+  redis:
+    image: redis:7
+    restart: unless-stopped
+    ports:
+      - "6379:6379"
+    volumes:
+      - redis-data:/data
+volumes:
+  redis-data:
+`,
+}
+
+// ContainerPresetNames returns the names of the catalog presets in alphabetical order
+func ContainerPresetNames() []string {
+	names := make([]string, 0, len(ContainerPresets))
+	for name := range ContainerPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// containerDirectory is where the stack is written on the host
+const containerDirectory = "/opt/terralu"
+
+// GenerateTerraformContainerConfig generates the Terraform configuration for a VM that installs Docker and starts the stack.
+// User data set on the host is merged into the generated cloud-config; its runcmd runs once the stack is up
+func (t *TerraluImpl) GenerateTerraformContainerConfig(container *ContainerInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(container)
+	if err != nil {
+		return "", fmt.Errorf("error validating the container instance: %w", err)
+	}
+	compose, err := readCompose(container)
+	if err != nil {
+		return "", err
+	}
+	userData, err := dockerCloudConfig(compose, container.Environment)
+	if err != nil {
+		return "", err
+	}
+
+	host := container.Host
+	hostUserData, err := readUserData(&host.OptionalFields)
+	if err != nil {
+		return "", fmt.Errorf("host user data: %w", err)
+	}
+	if hostUserData != "" {
+		userData, err = mergeCloudConfig(hostUserData, userData)
+		if err != nil {
+			return "", err
+		}
+	}
+	host.OptionalFields.UserData = userData
+	host.OptionalFields.UserDataPath = ""
+	return t.GenerateTerraformVirtualMachineConfig(&host)
+}
+
+// readCompose returns the docker-compose file of the container, making sure it declares services
+func readCompose(container *ContainerInstance) (string, error) {
+	compose := container.Compose
+	switch {
+	case container.Preset != "":
+		preset, ok := ContainerPresets[container.Preset]
+		if !ok {
+			return "", fmt.Errorf("unknown container preset %q", container.Preset)
+		}
+		compose = preset
+	case compose == "":
+		content, err := os.ReadFile(container.ComposePath)
+		if err != nil {
+			return "", fmt.Errorf("error reading the docker-compose file: %w", err)
+		}
+		compose = string(content)
+	}
+
+	var parsed struct {
+		Services map[string]any `yaml:"services"`
+	}
+	err := yaml.Unmarshal([]byte(compose), &parsed)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the docker-compose file: %w", err)
+	}
+	if len(parsed.Services) == 0 {
+		return "", fmt.Errorf("docker-compose file declares no services")
+	}
+	return compose, nil
+}
+
+// cloudConfig is the subset of cloud-config used to bootstrap Docker hosts
+type cloudConfig struct {
+	WriteFiles []cloudConfigFile `yaml:"write_files"`
+	RunCmd     []string          `yaml:"runcmd"`
+}
+
+// cloudConfigFile is an entry of the cloud-config write_files directive
+type cloudConfigFile struct {
+	Path        string `yaml:"path"`
+	Permissions string `yaml:"permissions,omitempty"`
+	Encoding    string `yaml:"encoding"`
+	Content     string `yaml:"content"`
+}
+
+// mergeCloudConfig adds the write_files and runcmd entries of the generated cloud-config before those of the host
+// cloud-config and keeps the other directives of the host as they are
+func mergeCloudConfig(host, generated string) (string, error) {
+	var hostDocument, generatedDocument map[string]any
+	err := yaml.Unmarshal([]byte(host), &hostDocument)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the host user data: %w", err)
+	}
+	err = yaml.Unmarshal([]byte(generated), &generatedDocument)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the cloud-config: %w", err)
+	}
+	for _, key := range []string{"write_files", "runcmd"} {
+		entries, ok := hostDocument[key].([]any)
+		if _, set := hostDocument[key]; set && !ok {
+			return "", fmt.Errorf("host user data: %s must be a list", key)
+		}
+		hostDocument[key] = append(generatedDocument[key].([]any), entries...)
+	}
+	content, err := yaml.Marshal(hostDocument)
+	if err != nil {
+		return "", fmt.Errorf("error encoding the cloud-config: %w", err)
+	}
+	return cloudConfigHeader + "\n" + string(content), nil
+}
+
+// dockerCloudConfig renders the cloud-config that installs Docker and starts the compose stack
+func dockerCloudConfig(compose string, environment map[string]string) (string, error) {
+	var env bytes.Buffer
+	keys := make([]string, 0, len(environment))
+	for key := range environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&env, "%s=%s\n", key, environment[key])
+	}
+
+	config := cloudConfig{
+		WriteFiles: []cloudConfigFile{
+			{
+				Path:     containerDirectory + "/docker-compose.yml",
+				Encoding: "b64",
+				Content:  base64.StdEncoding.EncodeToString([]byte(compose)),
+			},
+			{
+				Path:        containerDirectory + "/.env",
+				Permissions: "0600",
+				Encoding:    "b64",
+				Content:     base64.StdEncoding.EncodeToString(env.Bytes()),
+			},
+		},
+		RunCmd: []string{
+			"curl -fsSL https://get.docker.com | sh",
+			"systemctl enable --now docker",
+			"docker compose --project-directory " + containerDirectory + " up -d",
+		},
+	}
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error encoding the cloud-config: %w", err)
+	}
+	return cloudConfigHeader + "\n" + string(content), nil
+}
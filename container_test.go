@@ -0,0 +1,180 @@
+package terralu
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformContainerConfig tests the GenerateTerraformContainerConfig method
+func TestTerraluImpl_GenerateTerraformContainerConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	host := VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{
+			Name:        "docker",
+			MachineType: &MachineTypeSchema{Name: "cloud-bs1.xsmall"},
+			Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+			SSHKeyName:  "key",
+		},
+	}
+	composePath := filepath.Join(t.TempDir(), "docker-compose.yml")
+	if err := os.WriteFile(composePath, []byte("services:\n  app:\n    image: busybox\n"), 0644); err != nil {
+		t.Fatalf("error writing the compose file: %v", err)
+	}
+	tests := []struct {
+		name        string
+		container   *ContainerInstance
+		wantCompose string
+		wantEnv     string
+		wantErr     bool
+	}{
+		{
+			name:        "Preset",
+			container:   &ContainerInstance{Host: host, Preset: "nginx"},
+			wantCompose: ContainerPresets["nginx"],
+			wantEnv:     "",
+			wantErr:     false,
+		},
+		{
+			name: "Preset With Environment",
+			container: &ContainerInstance{
+				Host:        host,
+				Preset:      "postgres",
+				Environment: map[string]string{"POSTGRES_PASSWORD": "secret", "POSTGRES_DB": "app"},
+			},
+			wantCompose: ContainerPresets["postgres"],
+			wantEnv:     "POSTGRES_DB=app\nPOSTGRES_PASSWORD=secret\n",
+			wantErr:     false,
+		},
+		{
+			name:        "Custom Compose File",
+			container:   &ContainerInstance{Host: host, ComposePath: composePath},
+			wantCompose: "services:\n  app:\n    image: busybox\n",
+			wantErr:     false,
+		},
+		{
+			name:      "Unknown Preset",
+			container: &ContainerInstance{Host: host, Preset: "oracle"},
+			wantErr:   true,
+		},
+		{
+			name:      "Compose Without Services",
+			container: &ContainerInstance{Host: host, Compose: "version: '3'\n"},
+			wantErr:   true,
+		},
+		{
+			name:      "Preset And Compose",
+			container: &ContainerInstance{Host: host, Preset: "nginx", Compose: "services: {}"},
+			wantErr:   true,
+		},
+		{
+			name:      "Neither Preset Nor Compose",
+			container: &ContainerInstance{Host: host},
+			wantErr:   true,
+		},
+	}
+
+	userDataPattern := regexp.MustCompile(`user_data\s*=\s*"([^"]+)"`)
+	contentPattern := regexp.MustCompile(`content: (\S*)`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformContainerConfig(tt.container)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !strings.Contains(got, `resource "mgc_virtual_machine_instances" "docker"`) {
+				t.Fatalf("expected the host VM, got %v", got)
+			}
+			match := userDataPattern.FindStringSubmatch(got)
+			if match == nil {
+				t.Fatalf("expected user_data, got %v", got)
+			}
+			cloudConfig, err := base64.StdEncoding.DecodeString(match[1])
+			if err != nil {
+				t.Fatalf("user_data is not base64: %v", err)
+			}
+			if !strings.HasPrefix(string(cloudConfig), "#cloud-config\n") {
+				t.Errorf("user_data is not a cloud-config: %s", cloudConfig)
+			}
+			contents := contentPattern.FindAllStringSubmatch(string(cloudConfig), -1)
+			if len(contents) != 2 {
+				t.Fatalf("expected compose and env files, got %s", cloudConfig)
+			}
+			compose, _ := base64.StdEncoding.DecodeString(contents[0][1])
+			if string(compose) != tt.wantCompose {
+				t.Errorf("compose = %q, want %q", compose, tt.wantCompose)
+			}
+			env, _ := base64.StdEncoding.DecodeString(contents[1][1])
+			if string(env) != tt.wantEnv {
+				t.Errorf("env = %q, want %q", env, tt.wantEnv)
+			}
+		})
+	}
+}
+
+// TestTerraluImpl_GenerateTerraformContainerConfig_HostUserData tests that the user data of the host is merged
+// into the Docker cloud-config instead of being dropped
+func TestTerraluImpl_GenerateTerraformContainerConfig_HostUserData(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	hostUserData := "#cloud-config\npackages:\n  - htop\nruncmd:\n  - echo ready\n"
+	userDataPath := filepath.Join(t.TempDir(), "user-data.yaml")
+	if err := os.WriteFile(userDataPath, []byte(hostUserData), 0644); err != nil {
+		t.Fatalf("error writing the user data file: %v", err)
+	}
+	tests := []struct {
+		name    string
+		fields  VirtualMachineOptionalFields
+		wantErr bool
+	}{
+		{name: "Inline User Data", fields: VirtualMachineOptionalFields{UserData: hostUserData}},
+		{name: "User Data File", fields: VirtualMachineOptionalFields{UserDataPath: userDataPath}},
+		{name: "Runcmd Not A List", fields: VirtualMachineOptionalFields{UserData: "#cloud-config\nruncmd: reboot\n"}, wantErr: true},
+	}
+
+	userDataPattern := regexp.MustCompile(`user_data\s*=\s*"([^"]+)"`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := New(pInfo, InMemory())
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			host := *newTestVirtualMachine("docker", "cloud-bs1.xsmall")
+			host.OptionalFields = tt.fields
+			got, err := tr.GenerateTerraformContainerConfig(&ContainerInstance{Host: host, Preset: "nginx"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			match := userDataPattern.FindStringSubmatch(got)
+			if match == nil {
+				t.Fatalf("expected user_data, got %v", got)
+			}
+			cloudConfig, _ := base64.StdEncoding.DecodeString(match[1])
+			if err := ValidateCloudConfig(string(cloudConfig)); err != nil {
+				t.Errorf("merged user data is not a cloud-config: %v", err)
+			}
+			for _, want := range []string{"packages:\n    - htop\n", "docker-compose.yml", "up -d\n    - echo ready\n"} {
+				if !strings.Contains(string(cloudConfig), want) {
+					t.Errorf("expected %q in the merged user data:\n%s", want, cloudConfig)
+				}
+			}
+		})
+	}
+}
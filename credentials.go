@@ -0,0 +1,219 @@
+package terralu
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+const (
+	apiKeyVariable    = "api_key"
+	keyIDVariable     = "key_id"
+	keySecretVariable = "key_secret"
+
+	tfvarsFile    = "terraform.tfvars"
+	gitignoreFile = ".gitignore"
+)
+
+// gitignore keeps the credentials and the Terraform state out of version control
+const gitignore = `terraform.tfvars
+.terraform/
+*.tfstate
+*.tfstate.*
+`
+
+// WithEnvironmentCredentials leaves the credentials out of terraform.tfvars; Terraform then reads them
+// from the TF_VAR_ environment variables listed by CredentialsEnvironment
+func WithEnvironmentCredentials() Option {
+	return func(t *TerraluImpl) {
+		t.environmentCredentials = true
+	}
+}
+
+// credentialVariables returns the credentials passed to the provider as Terraform variable names and values
+func credentialVariables(credentials *TerraluProviderInfo) []hcl.ObjectItem {
+	variables := []hcl.ObjectItem{{Key: apiKeyVariable, Value: hcl.String(credentials.ApiKey)}}
+	if hasObjectStorageKeys(credentials) {
+		variables = append(variables,
+			hcl.ObjectItem{Key: keyIDVariable, Value: hcl.String(credentials.KeyID)},
+			hcl.ObjectItem{Key: keySecretVariable, Value: hcl.String(credentials.KeySecret)},
+		)
+	}
+	return variables
+}
+
+// hasObjectStorageKeys reports whether both halves of the object storage key pair are set
+func hasObjectStorageKeys(credentials *TerraluProviderInfo) bool {
+	return credentials.KeyID != "" && credentials.KeySecret != ""
+}
+
+// credentialVariableBlocks declares the credential variables as sensitive, so Terraform never prints them
+func credentialVariableBlocks(credentials *TerraluProviderInfo) []*hcl.Block {
+	descriptions := map[string]string{
+		apiKeyVariable:    "Magalu Cloud API key",
+		keyIDVariable:     "Magalu Cloud object storage key ID",
+		keySecretVariable: "Magalu Cloud object storage key secret",
+	}
+	var blocks []*hcl.Block
+	for _, variable := range credentialVariables(credentials) {
+		blocks = append(blocks, sensitiveVariable(variable.Key, descriptions[variable.Key]))
+	}
+	return blocks
+}
+
+// variableReference returns the expression reading a Terraform variable
+func variableReference(name string) hcl.Raw {
+	return hcl.Raw("var." + name)
+}
+
+// CredentialsEnvironment returns the credentials as TF_VAR_ environment variables in KEY=value form
+func CredentialsEnvironment(credentials *TerraluProviderInfo) []string {
+	var env []string
+	for _, variable := range credentialVariables(credentials) {
+		env = append(env, "TF_VAR_"+variable.Key+"="+string(variable.Value.(hcl.String)))
+	}
+	return env
+}
+
+// EnvironmentInstructions returns the shell commands exporting the credentials for Terraform
+func EnvironmentInstructions(credentials *TerraluProviderInfo) string {
+	var builder strings.Builder
+	for _, entry := range CredentialsEnvironment(credentials) {
+		name, value, _ := strings.Cut(entry, "=")
+		builder.WriteString("export " + name + "='" + strings.ReplaceAll(value, "'", `'\''`) + "'\n")
+	}
+	return builder.String()
+}
+
+// usesCredentialVariables reports whether the workspace declares the credential variables
+func (t *TerraluImpl) usesCredentialVariables() bool {
+	return t.declaresVariable(apiKeyVariable)
+}
+
+// declaresVariable reports whether the workspace declares the variable
+func (t *TerraluImpl) declaresVariable(name string) bool {
+	for _, block := range t.file.Blocks {
+		if block.Type == "variable" && len(block.Labels) == 1 && block.Labels[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveVariable declares a string variable Terraform never prints
+func sensitiveVariable(name, description string) *hcl.Block {
+	block := hcl.NewBlock("variable", name)
+	block.Body.
+		SetAttribute("description", hcl.String(description)).
+		SetAttribute("type", hcl.Raw("string")).
+		SetAttribute("sensitive", hcl.Bool(true))
+	return block
+}
+
+// writeCredentials writes terraform.tfvars with the credentials and the other secrets, readable by its owner only,
+// and makes sure .gitignore keeps it out of version control. Values already in the file are kept while their
+// variable is still declared. Only directory sinks receive the file: archives and writers end up in logs and
+// CI artifacts, so those workspaces read the values from TF_VAR_ variables
+func (t *TerraluImpl) writeCredentials() error {
+	if t.environmentCredentials || t.credentials == nil {
+		return nil
+	}
+	var values []hcl.ObjectItem
+	if t.usesCredentialVariables() {
+		values = credentialVariables(t.credentials)
+	}
+	values = append(values, t.secrets...)
+	if len(values) == 0 {
+		return nil
+	}
+	sink, ok := t.output().(*DirectorySink)
+	if !ok {
+		return nil
+	}
+	content, err := sink.readFile(tfvarsFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading %s: %w", tfvarsFile, err)
+	}
+	tfvars, err := hcl.ParseBody(content)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", tfvarsFile, err)
+	}
+	for _, attr := range tfvars.Attributes() {
+		if !t.declaresVariable(attr.Name) {
+			tfvars.RemoveAttribute(attr.Name)
+		}
+	}
+	for _, value := range values {
+		tfvars.SetAttribute(value.Key, value.Value)
+	}
+	err = sink.writePrivateFile(tfvarsFile, tfvars.Bytes())
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", tfvarsFile, err)
+	}
+	return updateGitignore(sink)
+}
+
+// updateGitignore appends the entries of gitignore missing from the workspace .gitignore, creating it if needed
+func updateGitignore(sink *DirectorySink) error {
+	content, err := sink.readFile(gitignoreFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading %s: %w", gitignoreFile, err)
+	}
+	existing := map[string]bool{}
+	for _, line := range strings.Split(string(content), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+	updated := string(content)
+	for _, entry := range strings.Split(strings.TrimSpace(gitignore), "\n") {
+		if existing[entry] {
+			continue
+		}
+		if updated != "" && !strings.HasSuffix(updated, "\n") {
+			updated += "\n"
+		}
+		updated += entry + "\n"
+	}
+	if updated == string(content) {
+		return nil
+	}
+	err = sink.WriteFile(gitignoreFile, []byte(updated))
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", gitignoreFile, err)
+	}
+	return nil
+}
+
+// credentialLookup resolves the variables referenced by the provider block, from terraform.tfvars when the
+// workspace has one and from the TF_VAR_ environment variables otherwise. The file is read on first use
+func (t *TerraluImpl) credentialLookup(dir string) func(name string) (string, error) {
+	var values map[string]string
+	return func(name string) (string, error) {
+		if values != nil {
+			return values[name], nil
+		}
+		content, err := t.filesystem().ReadFile(filepath.Join(dir, tfvarsFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			t.environmentCredentials = true
+			return os.Getenv("TF_VAR_" + name), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading %s: %w", tfvarsFile, err)
+		}
+		body, err := hcl.ParseBody(content)
+		if err != nil {
+			return "", fmt.Errorf("error parsing %s: %w", tfvarsFile, err)
+		}
+		values = map[string]string{}
+		for _, attr := range body.Attributes() {
+			if s, ok := attr.Value.(hcl.String); ok {
+				values[attr.Name] = string(s)
+			}
+		}
+		return values[name], nil
+	}
+}
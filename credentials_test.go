@@ -0,0 +1,167 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestCredentials tests that secrets only reach terraform.tfvars and are read back from it
+func TestCredentials(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access", KeyID: "key", KeySecret: "secret"}
+	dir := filepath.Join(t.TempDir(), "workspace")
+	tr, err := New(pInfo, WithDirectory(dir))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+
+	main, _ := os.ReadFile(filepath.Join(dir, "main.tf"))
+	for _, secret := range []string{"access", "secret"} {
+		if strings.Contains(string(main), `"`+secret+`"`) {
+			t.Errorf("main.tf should not contain %q:\n%s", secret, main)
+		}
+	}
+	tfvars, _ := os.ReadFile(filepath.Join(dir, "terraform.tfvars"))
+	want := "api_key    = \"access\"\nkey_id     = \"key\"\nkey_secret = \"secret\"\n"
+	if diff := cmp.Diff(want, string(tfvars)); diff != "" {
+		t.Errorf("terraform.tfvars mismatch (-want +got):\n%s", diff)
+	}
+	ignore, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if !strings.Contains(string(ignore), "terraform.tfvars\n") {
+		t.Errorf("expected terraform.tfvars to be git-ignored:\n%s", ignore)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "terraform.tfvars")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("terraform.tfvars should only be readable by its owner, got %v (%v)", info.Mode(), err)
+	}
+
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	if diff := cmp.Diff(pInfo, loaded.GetTerraluProviderInfo()); diff != "" {
+		t.Errorf("provider info mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestCredentials_Environment tests the environment variable mode
+func TestCredentials_Environment(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "it's", KeyID: "key", KeySecret: "secret"}
+	dir := filepath.Join(t.TempDir(), "workspace")
+	tr, err := New(pInfo, WithDirectory(dir), WithEnvironmentCredentials())
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "terraform.tfvars")); !os.IsNotExist(err) {
+		t.Errorf("expected no terraform.tfvars, got %v", err)
+	}
+
+	want := "export TF_VAR_api_key='it'\\''s'\nexport TF_VAR_key_id='key'\nexport TF_VAR_key_secret='secret'\n"
+	if diff := cmp.Diff(want, EnvironmentInstructions(pInfo)); diff != "" {
+		t.Errorf("EnvironmentInstructions mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, entry := range CredentialsEnvironment(pInfo) {
+		name, value, _ := strings.Cut(entry, "=")
+		t.Setenv(name, value)
+	}
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	if diff := cmp.Diff(pInfo, loaded.GetTerraluProviderInfo()); diff != "" {
+		t.Errorf("provider info mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := loaded.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+		t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "terraform.tfvars")); !os.IsNotExist(err) {
+		t.Errorf("a workspace loaded from the environment should not write terraform.tfvars, got %v", err)
+	}
+}
+
+// TestCredentials_DatabasePassword tests that database passwords go to terraform.tfvars and survive reloading
+func TestCredentials_DatabasePassword(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	dir := t.TempDir()
+	tr, err := New(pInfo, WithDirectory(dir))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	db := &DatabaseInstance{Name: "orders", EngineVersion: "8.0", InstanceType: "cloud-dbaas-bs1.small", VolumeSize: 20, User: "admin", Password: "supersecret"}
+	if _, err := tr.GenerateTerraformDatabaseConfig(db); err != nil {
+		t.Fatalf("GenerateTerraformDatabaseConfig error = %v", err)
+	}
+	main, _ := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if strings.Contains(string(main), "supersecret") {
+		t.Errorf("main.tf should not contain the password:\n%s", main)
+	}
+
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	db = &DatabaseInstance{Name: "billing", EngineVersion: "8.0", InstanceType: "cloud-dbaas-bs1.small", VolumeSize: 20, User: "admin"}
+	if _, err := loaded.GenerateTerraformDatabaseConfig(db); err != nil {
+		t.Fatalf("GenerateTerraformDatabaseConfig error = %v", err)
+	}
+	tfvars, _ := os.ReadFile(filepath.Join(dir, "terraform.tfvars"))
+	want := "api_key         = \"access\"\norders_password = \"supersecret\"\n"
+	if diff := cmp.Diff(want, string(tfvars)); diff != "" {
+		t.Errorf("terraform.tfvars mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestCredentials_Gitignore tests that a hand-written .gitignore is kept and only completed
+func TestCredentials_Gitignore(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n.terraform/"), 0644); err != nil {
+		t.Fatalf("error writing .gitignore: %v", err)
+	}
+	tr, err := New(pInfo, WithDirectory(dir))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+		t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+	}
+	ignore, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	want := "*.log\n.terraform/\nterraform.tfvars\n*.tfstate\n*.tfstate.*\n"
+	if diff := cmp.Diff(want, string(ignore)); diff != "" {
+		t.Errorf(".gitignore mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestCredentials_Sinks tests that the credentials never reach sinks other than a directory
+func TestCredentials_Sinks(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "SUPERSECRET"}
+	var out strings.Builder
+	tr, err := New(pInfo, WithSink(NewWriterSink(&out)))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close error = %v", err)
+	}
+	if strings.Contains(out.String(), "SUPERSECRET") || strings.Contains(out.String(), "terraform.tfvars") {
+		t.Errorf("the credentials were written to the sink:\n%s", out.String())
+	}
+}
@@ -0,0 +1,49 @@
+package terralu
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformDatabaseConfig generates the Terraform configuration for a MySQL database instance.
+// The password is read from a sensitive variable whose value goes to terraform.tfvars with the credentials
+func (t *TerraluImpl) GenerateTerraformDatabaseConfig(db *DatabaseInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(db)
+	if err != nil {
+		return "", fmt.Errorf("error validating the database instance: %w", err)
+	}
+
+	resource := t.newResource("mgc_dbaas_instances", db.Name)
+	resource.Body.
+		SetAttribute("name", hcl.String(db.Name)).
+		SetAttribute("engine_name", hcl.String("mysql")).
+		SetAttribute("engine_version", hcl.String(db.EngineVersion)).
+		SetAttribute("instance_type", hcl.String(db.InstanceType)).
+		SetAttribute("volume_size", hcl.Number(db.VolumeSize)).
+		SetAttribute("user", hcl.String(db.User)).
+		SetAttribute("password", variableReference(db.PasswordVariable()))
+	if db.BackupRetentionDays != 0 {
+		resource.Body.SetAttribute("backup_retention_days", hcl.Number(db.BackupRetentionDays))
+	}
+	if db.BackupStartAt != "" {
+		resource.Body.SetAttribute("backup_start_at", hcl.String(db.BackupStartAt))
+	}
+
+	password := sensitiveVariable(db.PasswordVariable(), "Password of the database "+db.Name)
+	blocks := append([]*hcl.Block{password, resource}, databaseOutputs(db)...)
+	if db.Password == "" {
+		return t.write(blocks...)
+	}
+	// The secret must be set before writing, since the write saves terraform.tfvars
+	count := len(t.secrets)
+	t.secrets = append(t.secrets, hcl.ObjectItem{Key: db.PasswordVariable(), Value: hcl.String(db.Password)})
+	manifest, err := t.write(blocks...)
+	if err != nil {
+		t.secrets = t.secrets[:count]
+		return "", err
+	}
+	return manifest, nil
+}
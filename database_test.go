@@ -0,0 +1,159 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformDatabaseConfig tests the GenerateTerraformDatabaseConfig method
+func TestTerraluImpl_GenerateTerraformDatabaseConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	tests := []struct {
+		name    string
+		db      *DatabaseInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Basic Database Config",
+			db: &DatabaseInstance{
+				Name:          "orders",
+				EngineVersion: "8.0",
+				InstanceType:  "cloud-dbaas-bs1.small",
+				VolumeSize:    20,
+				User:          "admin",
+				Password:      "supersecret",
+				SkipOutputs:   true,
+			},
+			want: `variable "orders_password" {
+			  description = "Password of the database orders"
+			  type        = string
+			  sensitive   = true
+			}
+
+			resource "mgc_dbaas_instances" "orders" {
+			  provider       = mgc.test
+			  name           = "orders"
+			  engine_name    = "mysql"
+			  engine_version = "8.0"
+			  instance_type  = "cloud-dbaas-bs1.small"
+			  volume_size    = 20
+			  user           = "admin"
+			  password       = var.orders_password
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Database Config With Backup",
+			db: &DatabaseInstance{
+				Name:                "orders",
+				EngineVersion:       "8.0",
+				InstanceType:        "cloud-dbaas-bs1.small",
+				VolumeSize:          20,
+				User:                "admin",
+				Password:            "supersecret",
+				BackupRetentionDays: 7,
+				BackupStartAt:       "04:00:00",
+				SkipOutputs:         true,
+			},
+			want: `variable "orders_password" {
+			  description = "Password of the database orders"
+			  type        = string
+			  sensitive   = true
+			}
+
+			resource "mgc_dbaas_instances" "orders" {
+			  provider       = mgc.test
+			  name           = "orders"
+			  engine_name    = "mysql"
+			  engine_version = "8.0"
+			  instance_type  = "cloud-dbaas-bs1.small"
+			  volume_size    = 20
+			  user           = "admin"
+			  password       = var.orders_password
+			  backup_retention_days = 7
+			  backup_start_at       = "04:00:00"
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Password From Environment",
+			db: &DatabaseInstance{
+				Name:          "orders",
+				EngineVersion: "8.0",
+				InstanceType:  "cloud-dbaas-bs1.small",
+				VolumeSize:    20,
+				User:          "admin",
+				SkipOutputs:   true,
+			},
+			want: `variable "orders_password" {
+			  description = "Password of the database orders"
+			  type        = string
+			  sensitive   = true
+			}
+
+			resource "mgc_dbaas_instances" "orders" {
+			  provider       = mgc.test
+			  name           = "orders"
+			  engine_name    = "mysql"
+			  engine_version = "8.0"
+			  instance_type  = "cloud-dbaas-bs1.small"
+			  volume_size    = 20
+			  user           = "admin"
+			  password       = var.orders_password
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Password Too Short",
+			db: &DatabaseInstance{
+				Name:          "orders",
+				EngineVersion: "8.0",
+				InstanceType:  "cloud-dbaas-bs1.small",
+				VolumeSize:    20,
+				User:          "admin",
+				Password:      "short",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Volume Too Small",
+			db: &DatabaseInstance{
+				Name:          "orders",
+				EngineVersion: "8.0",
+				InstanceType:  "cloud-dbaas-bs1.small",
+				VolumeSize:    5,
+				User:          "admin",
+				Password:      "supersecret",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformDatabaseConfig(tt.db)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package terralu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// terraformBinaries are the executables NewExecutor looks for on the PATH, in order
+var terraformBinaries = []string{"terraform", "tofu"}
+
+// Executor runs terraform or OpenTofu in a workspace directory
+type Executor struct {
+	binary string
+	dir    string
+	env    []string
+	output io.Writer
+}
+
+// ExecutorOption configures an Executor created by NewExecutor
+type ExecutorOption func(*Executor)
+
+// WithBinary sets the executable to run instead of looking for terraform or tofu on the PATH
+func WithBinary(path string) ExecutorOption {
+	return func(e *Executor) {
+		e.binary = path
+	}
+}
+
+// WithOutput sets where the standard output and error of the commands are streamed; they are discarded by default
+func WithOutput(w io.Writer) ExecutorOption {
+	return func(e *Executor) {
+		e.output = w
+	}
+}
+
+// WithCredentials passes the credentials to the commands as TF_VAR_ environment variables,
+// which workspaces created with WithEnvironmentCredentials need
+func WithCredentials(credentials *TerraluProviderInfo) ExecutorOption {
+	return func(e *Executor) {
+		e.env = append(e.env, CredentialsEnvironment(credentials)...)
+	}
+}
+
+// NewExecutor creates an executor for the workspace directory, finding terraform or tofu on the PATH
+// unless WithBinary is given
+func NewExecutor(dir string, opts ...ExecutorOption) (*Executor, error) {
+	executor := &Executor{dir: dir, output: io.Discard}
+	for _, opt := range opts {
+		opt(executor)
+	}
+	if executor.binary == "" {
+		for _, name := range terraformBinaries {
+			path, err := exec.LookPath(name)
+			if err == nil {
+				executor.binary = path
+				break
+			}
+		}
+		if executor.binary == "" {
+			return nil, fmt.Errorf("neither terraform nor tofu was found on the PATH")
+		}
+	}
+	return executor, nil
+}
+
+// Binary returns the path of the executable the commands run
+func (e *Executor) Binary() string {
+	return e.binary
+}
+
+// Init runs init, downloading the providers of the workspace
+func (e *Executor) Init(ctx context.Context) error {
+	return e.Run(ctx, "init", "-input=false")
+}
+
+// Plan runs plan and shows the changes apply would make
+func (e *Executor) Plan(ctx context.Context) error {
+	return e.Run(ctx, "plan", "-input=false")
+}
+
+// Apply runs apply without asking for confirmation, so callers must confirm with the user first
+func (e *Executor) Apply(ctx context.Context) error {
+	return e.Run(ctx, "apply", "-input=false", "-auto-approve")
+}
+
+// Destroy runs destroy without asking for confirmation, so callers must confirm with the user first
+func (e *Executor) Destroy(ctx context.Context) error {
+	return e.Run(ctx, "destroy", "-input=false", "-auto-approve")
+}
+
+// Run runs the executable with the given arguments in the workspace directory and waits for it to finish.
+// A non-zero exit status is returned as an error wrapping *exec.ExitError
+func (e *Executor) Run(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, e.binary, args...)
+	cmd.Dir = e.dir
+	cmd.Env = append(append(os.Environ(), "TF_IN_AUTOMATION=1"), e.env...)
+	cmd.Stdout = e.output
+	cmd.Stderr = e.output
+	err := cmd.Run()
+	if err != nil {
+		command := filepath.Base(e.binary)
+		if len(args) > 0 {
+			command += " " + args[0]
+		}
+		return fmt.Errorf("error running %s: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package terralu
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTerraform is a script standing in for terraform: it prints its directory, arguments and the API key
+// variable, and fails on destroy
+const fakeTerraform = `#!/bin/sh
+echo "dir=$(pwd) args=$* api_key=$TF_VAR_api_key automation=$TF_IN_AUTOMATION"
+if [ "$1" = destroy ]; then
+  echo "cannot destroy" >&2
+  exit 3
+fi
+`
+
+func writeFakeTerraform(t *testing.T, dir, name string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake terraform is a shell script")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(fakeTerraform), 0755); err != nil {
+		t.Fatalf("error writing the fake terraform: %v", err)
+	}
+	return path
+}
+
+// TestExecutor tests running terraform commands through a fake binary
+func TestExecutor(t *testing.T) {
+	binary := writeFakeTerraform(t, t.TempDir(), "terraform")
+	workspace := t.TempDir()
+	var output bytes.Buffer
+	executor, err := NewExecutor(workspace, WithBinary(binary), WithOutput(&output), WithCredentials(&TerraluProviderInfo{ApiKey: "access"}))
+	if err != nil {
+		t.Fatalf("NewExecutor error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		run      func(context.Context) error
+		wantArgs string
+		wantCode int
+	}{
+		{"Init", executor.Init, "init -input=false", 0},
+		{"Plan", executor.Plan, "plan -input=false", 0},
+		{"Apply", executor.Apply, "apply -input=false -auto-approve", 0},
+		{"Destroy", executor.Destroy, "destroy -input=false -auto-approve", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output.Reset()
+			err := tt.run(context.Background())
+			var exitErr *exec.ExitError
+			switch {
+			case tt.wantCode == 0 && err != nil:
+				t.Fatalf("unexpected error = %v", err)
+			case tt.wantCode != 0 && (!errors.As(err, &exitErr) || exitErr.ExitCode() != tt.wantCode):
+				t.Fatalf("expected exit status %d, got %v", tt.wantCode, err)
+			}
+			want := "dir=" + workspace + " args=" + tt.wantArgs + " api_key=access automation=1"
+			if !strings.Contains(output.String(), want) {
+				t.Errorf("expected %q in the output, got %q", want, output.String())
+			}
+		})
+	}
+	if !strings.Contains(output.String(), "cannot destroy") {
+		t.Errorf("expected the standard error in the output, got %q", output.String())
+	}
+}
+
+// TestNewExecutor_LookPath tests finding terraform or tofu on the PATH
+func TestNewExecutor_LookPath(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	if _, err := NewExecutor(t.TempDir()); err == nil {
+		t.Errorf("expected an error without terraform on the PATH")
+	}
+
+	tofu := writeFakeTerraform(t, bin, "tofu")
+	executor, err := NewExecutor(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewExecutor error = %v", err)
+	}
+	if executor.Binary() != tofu {
+		t.Errorf("Binary() = %s, want %s", executor.Binary(), tofu)
+	}
+
+	terraform := writeFakeTerraform(t, bin, "terraform")
+	executor, err = NewExecutor(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewExecutor error = %v", err)
+	}
+	if executor.Binary() != terraform {
+		t.Errorf("terraform should be preferred, Binary() = %s", executor.Binary())
+	}
+}
@@ -0,0 +1,128 @@
+package terralu
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileSystem is the storage workspaces are read from and written to
+type FileSystem interface {
+	MkdirAll(dir string) error
+	ReadFile(name string) ([]byte, error)
+	// WriteFile replaces the whole file, so readers never see a partial write
+	WriteFile(name string, data []byte) error
+}
+
+// OSFileSystem is the FileSystem backed by the local disk
+type OSFileSystem struct{}
+
+// MkdirAll creates the directory and its parents
+func (OSFileSystem) MkdirAll(dir string) error {
+	return os.MkdirAll(dir, os.ModePerm)
+}
+
+// ReadFile reads the whole file
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile writes the content to a temporary file first and renames it over the target
+func (f OSFileSystem) WriteFile(name string, data []byte) error {
+	return f.writeFile(name, data, 0644)
+}
+
+// WritePrivateFile is WriteFile for files only their owner may read, such as terraform.tfvars
+func (f OSFileSystem) WritePrivateFile(name string, data []byte) error {
+	return f.writeFile(name, data, 0600)
+}
+
+func (OSFileSystem) writeFile(name string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-*")
+	if err != nil {
+		return fmt.Errorf("error creating the temporary file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(data)
+	if err != nil {
+		temp.Close()
+		return fmt.Errorf("error writing to the temporary file: %w", err)
+	}
+	err = temp.Close()
+	if err != nil {
+		return fmt.Errorf("error closing the temporary file: %w", err)
+	}
+	err = os.Chmod(temp.Name(), perm)
+	if err != nil {
+		return fmt.Errorf("error setting the file permissions: %w", err)
+	}
+	err = os.Rename(temp.Name(), name)
+	if err != nil {
+		return fmt.Errorf("error replacing the file: %w", err)
+	}
+	return nil
+}
+
+// privateFileSystem is implemented by file systems that can restrict a file to its owner
+type privateFileSystem interface {
+	WritePrivateFile(name string, data []byte) error
+}
+
+// writePrivateFile writes a file only its owner may read, where the file system supports permissions
+func writePrivateFile(fs FileSystem, name string, data []byte) error {
+	if private, ok := fs.(privateFileSystem); ok {
+		return private.WritePrivateFile(name, data)
+	}
+	return fs.WriteFile(name, data)
+}
+
+// MemoryFileSystem is a FileSystem kept entirely in memory, safe for concurrent use
+type MemoryFileSystem struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemoryFileSystem creates an empty in-memory FileSystem
+func NewMemoryFileSystem() *MemoryFileSystem {
+	return &MemoryFileSystem{files: map[string][]byte{}}
+}
+
+// MkdirAll does nothing, directories are implied by file names
+func (m *MemoryFileSystem) MkdirAll(dir string) error {
+	return nil
+}
+
+// ReadFile returns a copy of the file content
+func (m *MemoryFileSystem) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile stores a copy of the content
+func (m *MemoryFileSystem) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+// Files returns the names of the stored files in alphabetical order
+func (m *MemoryFileSystem) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
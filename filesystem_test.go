@@ -0,0 +1,68 @@
+package terralu
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestFileSystems tests that the disk and memory file systems behave the same way
+func TestFileSystems(t *testing.T) {
+	for name, fileSystem := range map[string]FileSystem{
+		"os":     OSFileSystem{},
+		"memory": NewMemoryFileSystem(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "workspace")
+			path := filepath.Join(dir, "main.tf")
+			if err := fileSystem.MkdirAll(dir); err != nil {
+				t.Fatalf("MkdirAll error = %v", err)
+			}
+			if _, err := fileSystem.ReadFile(path); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("expected a not exist error, got %v", err)
+			}
+			for _, content := range []string{"first", "second"} {
+				if err := fileSystem.WriteFile(path, []byte(content)); err != nil {
+					t.Fatalf("WriteFile error = %v", err)
+				}
+				got, err := fileSystem.ReadFile(path)
+				if err != nil {
+					t.Fatalf("ReadFile error = %v", err)
+				}
+				if diff := cmp.Diff(content, string(got)); diff != "" {
+					t.Errorf("ReadFile mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+// TestOSFileSystem_WriteFile tests that no temporary files are left behind
+func TestOSFileSystem_WriteFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := (OSFileSystem{}).WriteFile(filepath.Join(dir, "main.tf"), []byte("content")); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only main.tf, got %v", entries)
+	}
+	if err := (OSFileSystem{}).WriteFile(filepath.Join(dir, "missing", "main.tf"), nil); err == nil {
+		t.Errorf("expected an error writing into a missing directory")
+	}
+}
+
+// TestMemoryFileSystem_Files tests that the stored files are listed in order
+func TestMemoryFileSystem_Files(t *testing.T) {
+	m := NewMemoryFileSystem()
+	m.WriteFile("b/main.tf", nil)
+	m.WriteFile("a/main.tf", nil)
+	m.WriteFile("./a/main.tf", []byte("x"))
+	if diff := cmp.Diff([]string{"a/main.tf", "b/main.tf"}, m.Files()); diff != "" {
+		t.Errorf("Files mismatch (-want +got):\n%s", diff)
+	}
+}
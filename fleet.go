@@ -0,0 +1,147 @@
+package terralu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// instanceKeys returns the instance names of a for_each fleet in alphabetical order
+func (v *VirtualMachineInstance) instanceKeys() []string {
+	keys := make([]string, 0, len(v.OptionalFields.Instances))
+	for key := range v.OptionalFields.Instances {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// firstInstance returns the index selecting the first instance of a fleet, or nothing for a single VM
+func (v *VirtualMachineInstance) firstInstance() string {
+	switch {
+	case v.OptionalFields.Count > 0:
+		return "[0]"
+	case len(v.OptionalFields.Instances) > 0:
+		return "[" + hcl.Quote(v.instanceKeys()[0]) + "]"
+	}
+	return ""
+}
+
+// setFleet sets count or for_each on the resource and returns the name, machine type and image expressions of each instance.
+// Instances are named <name>-<index> or <name>-<key>; with NameIsPrefix the provider still appends its random suffix
+func setFleet(resource *hcl.Block, vm *VirtualMachineInstance) (name, machineType, image hcl.Expression) {
+	required := vm.RequiredFields
+	switch {
+	case vm.OptionalFields.Count > 0:
+		resource.Body.SetAttribute("count", hcl.Number(vm.OptionalFields.Count))
+		return interpolation(required.Name+"-", "count.index"), hcl.String(required.MachineType.Name), hcl.String(required.Image.Name)
+	case len(vm.OptionalFields.Instances) > 0:
+		instances := hcl.Object{}
+		for _, key := range vm.instanceKeys() {
+			overrides := vm.OptionalFields.Instances[key]
+			instance := hcl.Object{
+				{Key: "machine_type", Value: hcl.String(orDefault(overrides.MachineType, required.MachineType.Name))},
+				{Key: "image", Value: hcl.String(orDefault(overrides.Image, required.Image.Name))},
+			}
+			instances = append(instances, hcl.ObjectItem{Key: key, Value: instance})
+		}
+		resource.Body.SetAttribute("for_each", instances)
+		return interpolation(required.Name+"-", "each.key"), hcl.Raw("each.value.machine_type"), hcl.Raw("each.value.image")
+	}
+	return hcl.String(required.Name), hcl.String(required.MachineType.Name), hcl.String(required.Image.Name)
+}
+
+// orDefault returns the value, or the fallback when the value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// fleetAttribute returns the expression collecting an attribute of every instance of the VM:
+// a list for count fleets and a map by instance name for for_each fleets
+func fleetAttribute(vm *VirtualMachineInstance, attribute string) hcl.Raw {
+	address := "mgc_virtual_machine_instances." + resourceName(vm.RequiredFields.Name)
+	switch {
+	case vm.OptionalFields.Count > 0:
+		return hcl.Raw(address + "[*]." + attribute)
+	case len(vm.OptionalFields.Instances) > 0:
+		return hcl.Raw("{ for key, vm in " + address + " : key => vm." + attribute + " }")
+	}
+	return hcl.Raw(address + "." + attribute)
+}
+
+// decodeFleet reads count and for_each back into the VM; the instance values written by setFleet
+// become explicit overrides, and the first instance provides the fleet's machine type and image
+func decodeFleet(body attributes, vm *VirtualMachineInstance) error {
+	name, isTemplate := body["name"].(hcl.Raw)
+	switch {
+	case body["count"] != nil:
+		count, ok := body["count"].(hcl.Number)
+		if !ok {
+			return fmt.Errorf("attribute count is not a number literal")
+		}
+		vm.OptionalFields.Count = int(count)
+		if !isTemplate {
+			return fmt.Errorf("attribute name is not a fleet name")
+		}
+		prefix, err := templatePrefix(string(name), "count.index")
+		if err != nil {
+			return err
+		}
+		vm.RequiredFields.Name = prefix
+	case body["for_each"] != nil:
+		instances, ok := body.object("for_each")
+		if !ok {
+			return fmt.Errorf("attribute for_each is not an object literal")
+		}
+		if !isTemplate {
+			return fmt.Errorf("attribute name is not a fleet name")
+		}
+		prefix, err := templatePrefix(string(name), "each.key")
+		if err != nil {
+			return err
+		}
+		vm.RequiredFields.Name = prefix
+		vm.OptionalFields.Instances = map[string]VirtualMachineOverrides{}
+		for _, item := range body["for_each"].(hcl.Object) {
+			instance, ok := instances.object(item.Key)
+			if !ok {
+				return fmt.Errorf("instance %q is not an object literal", item.Key)
+			}
+			var overrides VirtualMachineOverrides
+			if overrides.MachineType, err = instance.string("machine_type"); err != nil {
+				return err
+			}
+			if overrides.Image, err = instance.string("image"); err != nil {
+				return err
+			}
+			if vm.RequiredFields.MachineType == nil {
+				vm.RequiredFields.MachineType = &MachineTypeSchema{Name: overrides.MachineType}
+				vm.RequiredFields.Image = &ImageSchema{Name: overrides.Image}
+			}
+			vm.OptionalFields.Instances[item.Key] = overrides
+		}
+	}
+	return nil
+}
+
+// templatePrefix returns the literal part of a "<prefix>-${expression}" name template
+func templatePrefix(template, expression string) (string, error) {
+	literal, ok := strings.CutSuffix(template, "-${"+expression+`}"`)
+	if !ok {
+		return "", fmt.Errorf("name %s is not a fleet name", template)
+	}
+	prefix, err := hcl.ParseExpression([]byte(literal + `"`))
+	if err != nil {
+		return "", fmt.Errorf("name %s is not a fleet name: %w", template, err)
+	}
+	s, ok := prefix.(hcl.String)
+	if !ok {
+		return "", fmt.Errorf("name %s is not a fleet name", template)
+	}
+	return string(s), nil
+}
@@ -0,0 +1,178 @@
+package terralu
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// TestTerraluImpl_GenerateTerraformVirtualMachineConfig_Fleet tests count and for_each fleets
+func TestTerraluImpl_GenerateTerraformVirtualMachineConfig_Fleet(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	workers := newTestVirtualMachine("worker", "small")
+	workers.OptionalFields.Count = 5
+	workers.OptionalFields.SkipOutputs = true
+
+	nodes := newTestVirtualMachine("node", "small")
+	nodes.OptionalFields.NameIsPrefix = true
+	nodes.OptionalFields.Instances = map[string]VirtualMachineOverrides{
+		"b": {},
+		"a": {MachineType: "large", Image: "cloud-debian-12 LTS"},
+	}
+
+	both := newTestVirtualMachine("both", "small")
+	both.OptionalFields.Count = 2
+	both.OptionalFields.Instances = map[string]VirtualMachineOverrides{"a": {}}
+
+	badKey := newTestVirtualMachine("bad", "small")
+	badKey.OptionalFields.Instances = map[string]VirtualMachineOverrides{"not valid": {}}
+
+	tests := []struct {
+		name    string
+		vm      *VirtualMachineInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Count",
+			vm:   workers,
+			want: `resource "mgc_virtual_machine_instances" "worker" {
+			  provider = mgc.test
+			  count    = 5
+			  name     = "worker-${count.index}"
+			  machine_type = {
+			    name = "small"
+			  }
+			  image = {
+			    name = "cloud-ubuntu-22.04 LTS"
+			  }
+			  network = {
+			    associate_public_ip = false
+			  }
+			  ssh_key_name = "key"
+			}`,
+		},
+		{
+			name: "For Each With Overrides And Prefix",
+			vm:   nodes,
+			want: `resource "mgc_virtual_machine_instances" "node" {
+			  provider = mgc.test
+			  for_each = {
+			    a = {
+			      machine_type = "large"
+			      image        = "cloud-debian-12 LTS"
+			    }
+			    b = {
+			      machine_type = "small"
+			      image        = "cloud-ubuntu-22.04 LTS"
+			    }
+			  }
+			  name = "node-${each.key}"
+			  machine_type = {
+			    name = each.value.machine_type
+			  }
+			  image = {
+			    name = each.value.image
+			  }
+			  name_is_prefix = true
+			  network = {
+			    associate_public_ip = false
+			  }
+			  ssh_key_name = "key"
+			}
+
+			output "node_id" {
+			  description = "ID of the virtual machine node"
+			  value       = { for key, vm in mgc_virtual_machine_instances.node : key => vm.id }
+			}
+
+			output "node_private_ip" {
+			  description = "Private IPv4 of the virtual machine node"
+			  value       = { for key, vm in mgc_virtual_machine_instances.node : key => vm.network_interfaces[0].local_ipv4 }
+			}`,
+		},
+		{
+			name:    "Count And Instances",
+			vm:      both,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Instance Name",
+			vm:      badKey,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := New(pInfo, InMemory())
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			got, err := tr.GenerateTerraformVirtualMachineConfig(tt.vm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if diff := cmp.Diff(normalizeWhitespace(tt.want), normalizeWhitespace(strings.TrimSpace(got))); diff != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+// TestVirtualMachineFleet_Reference tests that references to a fleet point at its first instance
+func TestVirtualMachineFleet_Reference(t *testing.T) {
+	workers := newTestVirtualMachine("worker", "small")
+	workers.OptionalFields.Count = 3
+	nodes := newTestVirtualMachine("node", "small")
+	nodes.OptionalFields.Instances = map[string]VirtualMachineOverrides{"b": {}, "a": {}}
+
+	if got := workers.Reference().InterfaceExpression(); got != hcl.Raw("mgc_virtual_machine_instances.worker[0].network_interfaces[0].id") {
+		t.Errorf("unexpected count reference %v", got)
+	}
+	if got := nodes.Reference().Expression(); got != hcl.Raw(`mgc_virtual_machine_instances.node["a"].id`) {
+		t.Errorf("unexpected for_each reference %v", got)
+	}
+}
+
+// TestLoadTerralu_Fleet tests that fleets are read back from the workspace
+func TestLoadTerralu_Fleet(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	dir := filepath.Join(t.TempDir(), "workspace")
+	tr, err := New(pInfo, WithDirectory(dir))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	workers := newTestVirtualMachine("worker \"x\"", "small")
+	workers.OptionalFields.Count = 5
+	nodes := newTestVirtualMachine("node", "small")
+	nodes.OptionalFields.NameIsPrefix = true
+	nodes.OptionalFields.Instances = map[string]VirtualMachineOverrides{
+		"a": {MachineType: "large", Image: "cloud-ubuntu-22.04 LTS"},
+		"b": {MachineType: "small", Image: "cloud-ubuntu-22.04 LTS"},
+	}
+	for _, vm := range []*VirtualMachineInstance{workers, nodes} {
+		if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+			t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+		}
+	}
+
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	got, err := loaded.ListVirtualMachines()
+	if err != nil {
+		t.Fatalf("ListVirtualMachines error = %v", err)
+	}
+	nodes.RequiredFields.MachineType = &MachineTypeSchema{Name: "large"}
+	if diff := cmp.Diff([]*VirtualMachineInstance{workers, nodes}, got); diff != "" {
+		t.Errorf("ListVirtualMachines mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,39 @@
+package terralu
+
+import (
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// Format selects the syntax the workspace files are written in
+type Format int
+
+const (
+	// HCLFormat writes the native Terraform syntax to .tf files
+	HCLFormat Format = iota
+	// JSONFormat writes the Terraform JSON syntax to .tf.json files, which is easier for other tools to process.
+	// Workspaces in this format can be generated but not loaded back with LoadTerralu
+	JSONFormat
+)
+
+// WithFormat sets the syntax of the generated files, HCLFormat by default
+func WithFormat(format Format) Option {
+	return func(t *TerraluImpl) {
+		t.format = format
+	}
+}
+
+// fileName returns the name a layout file is written under in the workspace format
+func (t *TerraluImpl) fileName(name string) string {
+	if t.format == JSONFormat {
+		return name + ".json"
+	}
+	return name
+}
+
+// encode renders a file in the workspace format
+func (t *TerraluImpl) encode(file *hcl.File) []byte {
+	if t.format == JSONFormat {
+		return file.JSON()
+	}
+	return file.Bytes()
+}
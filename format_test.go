@@ -0,0 +1,78 @@
+package terralu
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestJSONFormat tests writing the workspace in the Terraform JSON syntax
+func TestJSONFormat(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	tests := []struct {
+		name   string
+		layout Layout
+		files  []string
+	}{
+		{"Single File", SingleFileLayout, []string{".gitignore", "main.tf.json", "terraform.tfvars"}},
+		{"Multiple Files", MultiFileLayout, []string{".gitignore", "outputs.tf.json", "providers.tf.json", "terraform.tfvars", "variables.tf.json", "vms.tf.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewMemoryFileSystem()
+			dir := filepath.Join(t.TempDir(), "workspace")
+			tr, err := New(pInfo, WithDirectory(dir), WithFileSystem(fs), WithLayout(tt.layout), WithFormat(JSONFormat))
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+				t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+			}
+			vm := newTestVirtualMachine("web", "small")
+			vm.OptionalFields.Count = 2
+			manifest, err := tr.GenerateTerraformVirtualMachineConfig(vm)
+			if err != nil {
+				t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+			}
+
+			var resource struct {
+				Resource map[string]map[string]map[string]any `json:"resource"`
+			}
+			if err := json.Unmarshal([]byte(manifest), &resource); err != nil {
+				t.Fatalf("the manifest is not valid JSON: %v\n%s", err, manifest)
+			}
+			web := resource.Resource["mgc_virtual_machine_instances"]["web"]
+			want := map[string]any{"provider": "mgc.test", "count": float64(2), "name": "web-${count.index}"}
+			for key, value := range want {
+				if diff := cmp.Diff(value, web[key]); diff != "" {
+					t.Errorf("%s mismatch (-want +got):\n%s", key, diff)
+				}
+			}
+
+			var names []string
+			for _, name := range fs.Files() {
+				rel, _ := filepath.Rel(dir, name)
+				names = append(names, rel)
+				content, _ := fs.ReadFile(name)
+				if filepath.Ext(name) == ".json" && !json.Valid(content) {
+					t.Errorf("%s is not valid JSON:\n%s", rel, content)
+				}
+			}
+			if diff := cmp.Diff(tt.files, names); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+			if !json.Valid([]byte(tr.Render())) {
+				t.Errorf("Render is not valid JSON:\n%s", tr.Render())
+			}
+			updated, err := tr.UpdateVirtualMachine("web", newTestVirtualMachine("web", "large"))
+			if err != nil || !json.Valid([]byte(updated)) {
+				t.Errorf("UpdateVirtualMachine should return JSON, got %v:\n%s", err, updated)
+			}
+			if _, err := LoadTerralu(dir, WithFileSystem(fs)); err == nil {
+				t.Errorf("expected an error loading a JSON workspace")
+			}
+		})
+	}
+}
@@ -3,8 +3,11 @@ module github.com/joaogabriel01/terralu
 go 1.23.2
 
 require (
+	github.com/go-playground/validator/v10 v10.22.1
+	github.com/google/go-cmp v0.6.0
 	github.com/google/uuid v1.6.0
 	github.com/rivo/tview v0.0.0-20241030223020-e34b54cd4c27
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -13,8 +16,6 @@ require (
 	github.com/gdamore/tcell/v2 v2.7.4 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/go-playground/validator/v10 v10.22.1 // indirect
-	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
@@ -0,0 +1,286 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// indentation is the whitespace added for every nesting level
+const indentation = "  "
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// IsIdentifier reports whether s can be used as a bare attribute name, object key or resource name
+func IsIdentifier(s string) bool {
+	return identifierPattern.MatchString(s)
+}
+
+// Identifier turns s into a valid identifier by replacing the characters HCL does not allow with underscores
+func Identifier(s string) string {
+	if IsIdentifier(s) {
+		return s
+	}
+	var builder strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			builder.WriteRune(r)
+		case i > 0 && (r == '-' || r >= '0' && r <= '9'):
+			builder.WriteRune(r)
+		case i == 0 && r >= '0' && r <= '9':
+			builder.WriteRune('_')
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+	if builder.Len() == 0 {
+		return "_"
+	}
+	return builder.String()
+}
+
+// Quote returns s as an HCL quoted string, escaping quotes, control characters and template sequences
+func Quote(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			builder.WriteString(`\"`)
+		case c == '\\':
+			builder.WriteString(`\\`)
+		case c == '\n':
+			builder.WriteString(`\n`)
+		case c == '\r':
+			builder.WriteString(`\r`)
+		case c == '\t':
+			builder.WriteString(`\t`)
+		case (c == '$' || c == '%') && i+1 < len(s) && s[i+1] == '{':
+			builder.WriteByte(c)
+			builder.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&builder, `\u%04x`, c)
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// Bytes renders the file, separating top-level blocks with a blank line
+func (f *File) Bytes() []byte {
+	var buf bytes.Buffer
+	for i, block := range f.Blocks {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writeBlock(&buf, block, 0)
+	}
+	return buf.Bytes()
+}
+
+// Bytes renders the block on its own
+func (b *Block) Bytes() []byte {
+	var buf bytes.Buffer
+	writeBlock(&buf, b, 0)
+	return buf.Bytes()
+}
+
+// Bytes renders the body at the top level, the way .tfvars files are written
+func (b *Body) Bytes() []byte {
+	var buf bytes.Buffer
+	writeBody(&buf, b, 0)
+	return buf.Bytes()
+}
+
+// ExpressionBytes renders a single expression as it would appear on the right side of an attribute
+func ExpressionBytes(expr Expression) []byte {
+	var buf bytes.Buffer
+	writeExpression(&buf, expr, 0)
+	return buf.Bytes()
+}
+
+func writeIndent(buf *bytes.Buffer, level int) {
+	buf.WriteString(strings.Repeat(indentation, level))
+}
+
+func writeBlock(buf *bytes.Buffer, block *Block, level int) {
+	if block.Source != "" {
+		buf.WriteString(block.Source)
+		buf.WriteString("\n")
+		return
+	}
+	writeComments(buf, block.Comments, level)
+	writeIndent(buf, level)
+	buf.WriteString(block.Type)
+	for _, label := range block.Labels {
+		buf.WriteString(" ")
+		buf.WriteString(Quote(label))
+	}
+	if len(block.Body.items) == 0 {
+		buf.WriteString(" {}\n")
+		return
+	}
+	buf.WriteString(" {\n")
+	writeBody(buf, &block.Body, level+1)
+	writeIndent(buf, level)
+	buf.WriteString("}\n")
+}
+
+// writeBody writes the attributes and nested blocks of a body, with a blank line around nested blocks.
+// Comments are written with the item that follows them
+func writeBody(buf *bytes.Buffer, body *Body, level int) {
+	var attrs []*Attribute
+	flush := func() {
+		writeAssignments(buf, attributeItems(attrs), level)
+		attrs = nil
+	}
+	var comments []Comment
+	first, previousBlock := true, false
+	for i, item := range body.items {
+		if comment, ok := item.(Comment); ok {
+			comments = append(comments, comment)
+			if i+1 < len(body.items) {
+				continue
+			}
+			item = nil
+		}
+		_, isBlock := item.(*Block)
+		if !first && (isBlock || previousBlock) {
+			flush()
+			buf.WriteString("\n")
+		}
+		if len(comments) > 0 {
+			flush()
+			writeComments(buf, comments, level)
+			comments = nil
+		}
+		switch item := item.(type) {
+		case *Attribute:
+			attrs = append(attrs, item)
+		case *Block:
+			writeBlock(buf, item, level)
+		}
+		first, previousBlock = false, isBlock
+	}
+	flush()
+}
+
+func writeComments(buf *bytes.Buffer, comments []Comment, level int) {
+	for _, comment := range comments {
+		writeIndent(buf, level)
+		buf.WriteString(string(comment))
+		buf.WriteString("\n")
+	}
+}
+
+func attributeItems(attrs []*Attribute) []ObjectItem {
+	items := make([]ObjectItem, len(attrs))
+	for i, attr := range attrs {
+		items[i] = ObjectItem{Key: attr.Name, Value: attr.Value}
+	}
+	return items
+}
+
+// writeAssignments writes key = value lines, aligning the equals signs of consecutive single-line values
+// the way terraform fmt does; multi-line values break the alignment
+func writeAssignments(buf *bytes.Buffer, items []ObjectItem, level int) {
+	keys := make([]string, len(items))
+	values := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = item.Key
+		if !IsIdentifier(item.Key) {
+			keys[i] = Quote(item.Key)
+		}
+		var value bytes.Buffer
+		writeExpression(&value, item.Value, level)
+		values[i] = value.String()
+	}
+	for start := 0; start < len(items); {
+		end := start + 1
+		width := len(keys[start])
+		if !strings.Contains(values[start], "\n") {
+			for end < len(items) && !strings.Contains(values[end], "\n") {
+				width = max(width, len(keys[end]))
+				end++
+			}
+		}
+		for i := start; i < end; i++ {
+			writeIndent(buf, level)
+			buf.WriteString(keys[i])
+			buf.WriteString(strings.Repeat(" ", width-len(keys[i])))
+			buf.WriteString(" = ")
+			buf.WriteString(values[i])
+			buf.WriteString("\n")
+		}
+		start = end
+	}
+}
+
+func writeExpression(buf *bytes.Buffer, expr Expression, level int) {
+	switch e := expr.(type) {
+	case String:
+		buf.WriteString(Quote(string(e)))
+	case Number:
+		buf.WriteString(strconv.Itoa(int(e)))
+	case Bool:
+		buf.WriteString(strconv.FormatBool(bool(e)))
+	case Raw:
+		buf.WriteString(string(e))
+	case List:
+		writeList(buf, e, level)
+	case Object:
+		writeObject(buf, e, level)
+	case nil:
+		buf.WriteString("null")
+	default:
+		panic(fmt.Sprintf("hcl: unsupported expression %T", expr))
+	}
+}
+
+// writeList writes scalar lists on a single line and lists of collections one item per line
+func writeList(buf *bytes.Buffer, list List, level int) {
+	multiline := false
+	for _, item := range list {
+		switch item.(type) {
+		case List, Object:
+			multiline = true
+		}
+	}
+	if !multiline {
+		buf.WriteString("[")
+		for i, item := range list {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeExpression(buf, item, level)
+		}
+		buf.WriteString("]")
+		return
+	}
+	buf.WriteString("[\n")
+	for _, item := range list {
+		writeIndent(buf, level+1)
+		writeExpression(buf, item, level+1)
+		buf.WriteString(",\n")
+	}
+	writeIndent(buf, level)
+	buf.WriteString("]")
+}
+
+func writeObject(buf *bytes.Buffer, object Object, level int) {
+	if len(object) == 0 {
+		buf.WriteString("{}")
+		return
+	}
+	buf.WriteString("{\n")
+	writeAssignments(buf, object, level+1)
+	writeIndent(buf, level)
+	buf.WriteString("}")
+}
@@ -0,0 +1,159 @@
+// Package hcl is a small in-memory model of HCL documents that always renders syntactically valid,
+// consistently formatted Terraform configuration
+package hcl
+
+// Expression is the value side of an attribute
+type Expression interface {
+	isExpression()
+}
+
+// String is a quoted string literal; quotes, escapes and template sequences are escaped on output
+type String string
+
+// Number is an integer literal
+type Number int
+
+// Bool is a boolean literal
+type Bool bool
+
+// Raw is an expression rendered verbatim, such as a reference (mgc_network_vpcs.main.id) or a function call
+type Raw string
+
+// List is a tuple of expressions
+type List []Expression
+
+// Object is an object constructor whose items keep their insertion order
+type Object []ObjectItem
+
+// ObjectItem is a key/value pair of an Object
+type ObjectItem struct {
+	Key   string
+	Value Expression
+}
+
+func (String) isExpression() {}
+func (Number) isExpression() {}
+func (Bool) isExpression()   {}
+func (Raw) isExpression()    {}
+func (List) isExpression()   {}
+func (Object) isExpression() {}
+
+// Strings builds a List of String literals
+func Strings(values []string) List {
+	list := make(List, 0, len(values))
+	for _, value := range values {
+		list = append(list, String(value))
+	}
+	return list
+}
+
+// Attribute is a name = value pair inside a body
+type Attribute struct {
+	Name  string
+	Value Expression
+}
+
+// Comment is a comment written on its own line, kept with its # // or /* */ markers
+type Comment string
+
+// Block is a block such as resource "type" "name" { ... }.
+// Source holds the original text of blocks read by Parse; when set it is written verbatim instead of Body.
+// Comments are the comments written above a top-level block, which Parse also keeps in Source
+type Block struct {
+	Type     string
+	Labels   []string
+	Body     Body
+	Source   string
+	Comments []Comment
+}
+
+// NewBlock creates an empty block with the given type and labels
+func NewBlock(blockType string, labels ...string) *Block {
+	return &Block{Type: blockType, Labels: labels}
+}
+
+// Body holds the attributes and nested blocks of a block, in order
+type Body struct {
+	items []any
+}
+
+// SetAttribute sets the attribute, replacing its value in place if it already exists
+func (b *Body) SetAttribute(name string, value Expression) *Body {
+	for _, item := range b.items {
+		if attr, ok := item.(*Attribute); ok && attr.Name == name {
+			attr.Value = value
+			return b
+		}
+	}
+	b.items = append(b.items, &Attribute{Name: name, Value: value})
+	return b
+}
+
+// Attribute returns the attribute with the given name
+func (b *Body) Attribute(name string) (*Attribute, bool) {
+	for _, item := range b.items {
+		if attr, ok := item.(*Attribute); ok && attr.Name == name {
+			return attr, true
+		}
+	}
+	return nil, false
+}
+
+// RemoveAttribute removes the attribute with the given name, if present
+func (b *Body) RemoveAttribute(name string) {
+	for i, item := range b.items {
+		if attr, ok := item.(*Attribute); ok && attr.Name == name {
+			b.items = append(b.items[:i], b.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// AppendBlock appends a nested block and returns it
+func (b *Body) AppendBlock(block *Block) *Block {
+	b.items = append(b.items, block)
+	return block
+}
+
+// AppendComment appends a comment on its own line
+func (b *Body) AppendComment(comment Comment) {
+	b.items = append(b.items, comment)
+}
+
+// Attributes returns the attributes of the body in order
+func (b *Body) Attributes() []*Attribute {
+	var attrs []*Attribute
+	for _, item := range b.items {
+		if attr, ok := item.(*Attribute); ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// Blocks returns the nested blocks of the body in order
+func (b *Body) Blocks() []*Block {
+	var blocks []*Block
+	for _, item := range b.items {
+		if block, ok := item.(*Block); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// Items returns the attributes (*Attribute), nested blocks (*Block) and comments (Comment) of the body in order
+func (b *Body) Items() []any {
+	return b.items
+}
+
+// File is an HCL document made of top-level blocks
+type File struct {
+	Blocks []*Block
+}
+
+// AppendBlock appends a top-level block and returns it
+func (f *File) AppendBlock(block *Block) *Block {
+	f.Blocks = append(f.Blocks, block)
+	return block
+}
@@ -0,0 +1,134 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestFile_Bytes tests rendering of blocks, attributes and expressions
+func TestFile_Bytes(t *testing.T) {
+	tests := []struct {
+		name string
+		file func() *File
+		want string
+	}{
+		{
+			name: "Empty Block",
+			file: func() *File {
+				return &File{Blocks: []*Block{NewBlock("terraform")}}
+			},
+			want: "terraform {}\n",
+		},
+		{
+			name: "Aligned Attributes And Nested Blocks",
+			file: func() *File {
+				resource := NewBlock("resource", "mgc_virtual_machine_instances", "web")
+				resource.Body.
+					SetAttribute("provider", Raw("mgc.test")).
+					SetAttribute("name", String("web")).
+					SetAttribute("machine_type", Object{{Key: "name", Value: String("cloud-bs1.xsmall")}}).
+					SetAttribute("name_is_prefix", Bool(true)).
+					SetAttribute("size", Number(20))
+				lifecycle := resource.Body.AppendBlock(NewBlock("lifecycle"))
+				lifecycle.Body.SetAttribute("prevent_destroy", Bool(true))
+				resource.Body.SetAttribute("tags", Strings([]string{"a", "b"}))
+
+				output := NewBlock("output", "id")
+				output.Body.SetAttribute("value", Raw("mgc_virtual_machine_instances.web.id"))
+				return &File{Blocks: []*Block{resource, output}}
+			},
+			want: `resource "mgc_virtual_machine_instances" "web" {
+  provider = mgc.test
+  name     = "web"
+  machine_type = {
+    name = "cloud-bs1.xsmall"
+  }
+  name_is_prefix = true
+  size           = 20
+
+  lifecycle {
+    prevent_destroy = true
+  }
+
+  tags = ["a", "b"]
+}
+
+output "id" {
+  value = mgc_virtual_machine_instances.web.id
+}
+`,
+		},
+		{
+			name: "Escaped Strings And Quoted Keys",
+			file: func() *File {
+				block := NewBlock("resource", "type", `we"ird`)
+				block.Body.
+					SetAttribute("name", String("say \"hi\"\n\\ ${var} %{if}")).
+					SetAttribute("labels", Object{
+						{Key: "app.kubernetes.io/name", Value: String("web")},
+						{Key: "tier", Value: String("app")},
+					}).
+					SetAttribute("taints", List{
+						Object{{Key: "key", Value: String("dedicated")}},
+					}).
+					SetAttribute("empty", List{})
+				return &File{Blocks: []*Block{block}}
+			},
+			want: `resource "type" "we\"ird" {
+  name = "say \"hi\"\n\\ $${var} %%{if}"
+  labels = {
+    "app.kubernetes.io/name" = "web"
+    tier                     = "app"
+  }
+  taints = [
+    {
+      key = "dedicated"
+    },
+  ]
+  empty = []
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(tt.file().Bytes())
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+// TestBody_SetAttribute tests that setting an existing attribute replaces it in place
+func TestBody_SetAttribute(t *testing.T) {
+	var body Body
+	body.SetAttribute("a", Number(1)).SetAttribute("b", Number(2)).SetAttribute("a", Number(3))
+	attrs := body.Attributes()
+	if len(attrs) != 2 || attrs[0].Name != "a" || attrs[0].Value != Number(3) {
+		t.Errorf("unexpected attributes %+v", attrs)
+	}
+	body.RemoveAttribute("a")
+	if _, ok := body.Attribute("a"); ok {
+		t.Errorf("attribute a should have been removed")
+	}
+}
+
+// TestIdentifier tests the conversion of arbitrary names into identifiers
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"web":       "web",
+		"prod-vm":   "prod-vm",
+		`we"ird vm`: "we_ird_vm",
+		"1st":       "_1st",
+		"-lead":     "_lead",
+		"":          "_",
+	}
+	for input, want := range tests {
+		if got := Identifier(input); got != want {
+			t.Errorf("Identifier(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
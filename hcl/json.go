@@ -0,0 +1,304 @@
+package hcl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSON renders the file in the Terraform JSON syntax, the content of a .tf.json file.
+//
+// Blocks are grouped by type and labels in the order they first appear, and blocks sharing the same type and
+// labels become arrays. String literals have their template sequences escaped, and Raw expressions are written as
+// "${expression}" templates, except where Terraform expects the bare expression text, such as depends_on or the
+// provider of a resource. Comments are not carried over
+func (f *File) JSON() []byte {
+	var buf bytes.Buffer
+	writeJSONBlocks(&buf, f.Blocks, 0)
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// jsonTree groups blocks by type and then by each label, keeping the order in which keys first appear
+type jsonTree struct {
+	keys     []string
+	children map[string]*jsonTree
+	bodies   []*Body
+	// blockType is the type of the blocks below this node, which decides how their attributes are written
+	blockType string
+}
+
+func (n *jsonTree) add(path []string, block *Block) {
+	if len(path) == 0 {
+		n.bodies = append(n.bodies, &block.Body)
+		n.blockType = block.Type
+		return
+	}
+	if n.children == nil {
+		n.children = map[string]*jsonTree{}
+	}
+	child, ok := n.children[path[0]]
+	if !ok {
+		child = &jsonTree{}
+		n.children[path[0]] = child
+		n.keys = append(n.keys, path[0])
+	}
+	child.add(path[1:], block)
+}
+
+// writeJSONBlocks writes blocks as a JSON object keyed by block type
+func writeJSONBlocks(buf *bytes.Buffer, blocks []*Block, level int) {
+	tree := &jsonTree{}
+	for _, block := range blocks {
+		tree.add(append([]string{block.Type}, block.Labels...), block)
+	}
+	writeJSONTree(buf, tree, level)
+}
+
+func writeJSONTree(buf *bytes.Buffer, tree *jsonTree, level int) {
+	if len(tree.bodies) == 1 && len(tree.keys) == 0 {
+		writeJSONBody(buf, tree.bodies[0], tree.blockType, level)
+		return
+	}
+	if len(tree.bodies) == 0 {
+		writeJSONObject(buf, tree.keys, level, func(key string) {
+			writeJSONTree(buf, tree.children[key], level+1)
+		})
+		return
+	}
+	// Blocks sharing the same type and labels, or with a varying number of labels
+	count := len(tree.bodies)
+	if len(tree.keys) > 0 {
+		count++
+	}
+	writeJSONArray(buf, count, level, func(i int) {
+		if i < len(tree.bodies) {
+			writeJSONBody(buf, tree.bodies[i], tree.blockType, level+1)
+			return
+		}
+		writeJSONTree(buf, &jsonTree{keys: tree.keys, children: tree.children}, level+1)
+	})
+}
+
+// writeJSONBody writes the attributes and nested blocks of a body as one object; nested blocks of the same type
+// are grouped where the first of them appears
+func writeJSONBody(buf *bytes.Buffer, body *Body, blockType string, level int) {
+	nested := &jsonTree{}
+	var keys []string
+	attributes := map[string]*Attribute{}
+	for _, item := range body.items {
+		switch item := item.(type) {
+		case *Attribute:
+			keys = append(keys, item.Name)
+			attributes[item.Name] = item
+		case *Block:
+			if nested.children[item.Type] == nil {
+				keys = append(keys, item.Type)
+			}
+			nested.add(append([]string{item.Type}, item.Labels...), item)
+		}
+	}
+	writeJSONObject(buf, keys, level, func(key string) {
+		if attr, ok := attributes[key]; ok {
+			writeJSONExpression(buf, attr.Value, literalAttribute(blockType, key), level+1)
+			return
+		}
+		writeJSONTree(buf, nested.children[key], level+1)
+	})
+}
+
+// literalAttribute reports whether Terraform reads the attribute as bare expression text rather than as a template
+func literalAttribute(blockType, name string) bool {
+	switch {
+	case name == "depends_on":
+		return true
+	case name == "provider":
+		return blockType == "resource" || blockType == "data"
+	case name == "providers":
+		return blockType == "module"
+	case name == "type":
+		return blockType == "variable"
+	case name == "ignore_changes", name == "replace_triggered_by":
+		return blockType == "lifecycle"
+	}
+	return false
+}
+
+func writeJSONExpression(buf *bytes.Buffer, expr Expression, literal bool, level int) {
+	switch e := expr.(type) {
+	case String:
+		buf.WriteString(jsonString(escapeTemplate(string(e))))
+	case Number:
+		buf.WriteString(strconv.Itoa(int(e)))
+	case Bool:
+		buf.WriteString(strconv.FormatBool(bool(e)))
+	case Raw:
+		if literal {
+			buf.WriteString(jsonString(string(e)))
+		} else if template, ok := quotedTemplate(string(e)); ok {
+			buf.WriteString(jsonString(template))
+		} else {
+			buf.WriteString(jsonString("${" + string(e) + "}"))
+		}
+	case List:
+		writeJSONArray(buf, len(e), level, func(i int) {
+			writeJSONExpression(buf, e[i], literal, level+1)
+		})
+	case Object:
+		keys := make([]string, len(e))
+		values := map[string]Expression{}
+		for i, item := range e {
+			keys[i] = item.Key
+			values[item.Key] = item.Value
+		}
+		writeJSONObject(buf, keys, level, func(key string) {
+			writeJSONExpression(buf, values[key], literal, level+1)
+		})
+	case nil:
+		buf.WriteString("null")
+	default:
+		panic(fmt.Sprintf("hcl: unsupported expression %T", expr))
+	}
+}
+
+func writeJSONObject(buf *bytes.Buffer, keys []string, level int, writeValue func(key string)) {
+	if len(keys) == 0 {
+		buf.WriteString("{}")
+		return
+	}
+	buf.WriteString("{\n")
+	for i, key := range keys {
+		writeIndent(buf, level+1)
+		buf.WriteString(jsonString(key))
+		buf.WriteString(": ")
+		writeValue(key)
+		if i+1 < len(keys) {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	writeIndent(buf, level)
+	buf.WriteString("}")
+}
+
+func writeJSONArray(buf *bytes.Buffer, count int, level int, writeItem func(i int)) {
+	if count == 0 {
+		buf.WriteString("[]")
+		return
+	}
+	buf.WriteString("[\n")
+	for i := 0; i < count; i++ {
+		writeIndent(buf, level+1)
+		writeItem(i)
+		if i+1 < count {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	writeIndent(buf, level)
+	buf.WriteString("]")
+}
+
+// jsonString returns s as a JSON string without escaping HTML characters
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// escapeTemplate doubles the template sequences of a literal, since Terraform reads JSON strings as templates
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// quotedTemplate converts an expression made of a single quoted template, such as "https://${var.host}/", into the
+// template text a JSON string holds. It reports false for anything else
+func quotedTemplate(raw string) (string, bool) {
+	if len(raw) < 2 || raw[0] != '"' {
+		return "", false
+	}
+	var builder strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			if i != len(raw)-1 {
+				return "", false
+			}
+			return builder.String(), true
+		case c == '\n':
+			return "", false
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case '"', '\\':
+				builder.WriteByte(raw[i])
+			case 'u', 'U':
+				size := 4
+				if raw[i] == 'U' {
+					size = 8
+				}
+				if i+size >= len(raw) {
+					return "", false
+				}
+				r, err := strconv.ParseUint(raw[i+1:i+1+size], 16, 32)
+				if err != nil {
+					return "", false
+				}
+				builder.WriteRune(rune(r))
+				i += size
+			default:
+				return "", false
+			}
+		case (c == '$' || c == '%') && strings.HasPrefix(raw[i+1:], string(c)+"{"):
+			// An escaped sequence keeps the same escape in JSON
+			builder.WriteString(raw[i : i+3])
+			i += 2
+		case (c == '$' || c == '%') && strings.HasPrefix(raw[i+1:], "{"):
+			end, ok := templateSequenceEnd(raw, i+2)
+			if !ok {
+				return "", false
+			}
+			builder.WriteString(raw[i:end])
+			i = end - 1
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// templateSequenceEnd returns the index just after the brace closing the template sequence whose content
+// starts at start, skipping nested braces and quoted strings
+func templateSequenceEnd(raw string, start int) (int, bool) {
+	depth := 1
+	for i := start; i < len(raw); i++ {
+		switch raw[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		case '"':
+			for i++; i < len(raw) && raw[i] != '"'; i++ {
+				if raw[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,168 @@
+package hcl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestFile_JSON tests rendering files in the Terraform JSON syntax
+func TestFile_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		file func() *File
+		want string
+	}{
+		{
+			name: "Empty File",
+			file: func() *File { return &File{} },
+			want: "{}\n",
+		},
+		{
+			name: "Resources And Nested Blocks",
+			file: func() *File {
+				resource := NewBlock("resource", "mgc_object_storage_buckets", "logs")
+				resource.Body.
+					SetAttribute("provider", Raw("mgc.test")).
+					SetAttribute("bucket", String("logs-${env}")).
+					SetAttribute("count", Number(2)).
+					SetAttribute("enabled", Bool(true)).
+					SetAttribute("description", nil).
+					SetAttribute("tags", Strings([]string{"a", "b"})).
+					SetAttribute("depends_on", List{Raw("mgc_network_vpcs.main")})
+				lifecycle := resource.Body.AppendBlock(NewBlock("lifecycle"))
+				lifecycle.Body.SetAttribute("prevent_destroy", Bool(true))
+				other := NewBlock("resource", "mgc_network_vpcs", "main")
+				other.Body.SetAttribute("name", String("main"))
+
+				output := NewBlock("output", "url")
+				output.Body.SetAttribute("value", Raw(`"https://br-se1.magaluobjects.com/${mgc_object_storage_buckets.logs[0].bucket}"`))
+				variable := NewBlock("variable", "api_key")
+				variable.Body.SetAttribute("type", Raw("string")).SetAttribute("sensitive", Bool(true))
+				return &File{Blocks: []*Block{resource, output, other, variable}}
+			},
+			want: `{
+  "resource": {
+    "mgc_object_storage_buckets": {
+      "logs": {
+        "provider": "mgc.test",
+        "bucket": "logs-$${env}",
+        "count": 2,
+        "enabled": true,
+        "description": null,
+        "tags": [
+          "a",
+          "b"
+        ],
+        "depends_on": [
+          "mgc_network_vpcs.main"
+        ],
+        "lifecycle": {
+          "prevent_destroy": true
+        }
+      }
+    },
+    "mgc_network_vpcs": {
+      "main": {
+        "name": "main"
+      }
+    }
+  },
+  "output": {
+    "url": {
+      "value": "https://br-se1.magaluobjects.com/${mgc_object_storage_buckets.logs[0].bucket}"
+    }
+  },
+  "variable": {
+    "api_key": {
+      "type": "string",
+      "sensitive": true
+    }
+  }
+}
+`,
+		},
+		{
+			name: "Repeated Blocks And Expressions",
+			file: func() *File {
+				first := NewBlock("provider", "mgc")
+				first.Body.SetAttribute("alias", String("a")).SetAttribute("api_key", Raw("var.api_key"))
+				second := NewBlock("provider", "mgc")
+				second.Body.SetAttribute("alias", String("b")).SetAttribute("key_pair", Object{{Key: "key_id", Value: Raw(`upper("x")`)}})
+				rule := NewBlock("resource", "mgc_network_security_groups", "web")
+				for _, port := range []int{22, 80} {
+					rule.Body.AppendBlock(NewBlock("rule")).Body.SetAttribute("port", Number(port))
+				}
+				return &File{Blocks: []*Block{first, second, rule, NewBlock("terraform")}}
+			},
+			want: `{
+  "provider": {
+    "mgc": [
+      {
+        "alias": "a",
+        "api_key": "${var.api_key}"
+      },
+      {
+        "alias": "b",
+        "key_pair": {
+          "key_id": "${upper(\"x\")}"
+        }
+      }
+    ]
+  },
+  "resource": {
+    "mgc_network_security_groups": {
+      "web": {
+        "rule": [
+          {
+            "port": 22
+          },
+          {
+            "port": 80
+          }
+        ]
+      }
+    }
+  },
+  "terraform": {}
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.file().JSON()
+			if !json.Valid(got) {
+				t.Errorf("invalid JSON:\n%s", got)
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("JSON mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestQuotedTemplate tests converting quoted templates into JSON template text
+func TestQuotedTemplate(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   string
+		wantOk bool
+	}{
+		{`"web-${count.index}"`, "web-${count.index}", true},
+		{`"a\"b\né"`, "a\"b\né", true},
+		{`"$${literal} ${lookup(var.m, "}")}"`, `$${literal} ${lookup(var.m, "}")}`, true},
+		{`"a" == "b"`, "", false},
+		{`var.name`, "", false},
+		{`"${unterminated"`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := quotedTemplate(tt.raw)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("quotedTemplate(%s) = %q, %v, want %q, %v", tt.raw, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
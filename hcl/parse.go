@@ -0,0 +1,573 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parse reads an HCL document into a File.
+//
+// Literals, lists and objects are decoded into their Expression types; anything else (references, function calls,
+// templates, heredocs, operators) is kept as Raw source. Every top-level block keeps its original text, including the
+// comments above it, in Source so that blocks nobody touches are written back exactly as they were.
+// Comments on their own line are also kept in Comments and in the bodies, so they survive when Source is dropped
+func Parse(src []byte) (*File, error) {
+	p := &parser{src: src}
+	file := &File{}
+	previousEnd, lastStart := 0, 0
+	for {
+		comments := p.skipComments()
+		if p.eof() {
+			break
+		}
+		item, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		block, ok := item.(*Block)
+		if !ok {
+			return nil, p.errorf("unexpected top-level attribute %q", item.(*Attribute).Name)
+		}
+		block.Comments = comments
+		block.Source = strings.TrimSpace(string(src[previousEnd:p.pos]))
+		lastStart, previousEnd = previousEnd, p.pos
+		file.Blocks = append(file.Blocks, block)
+	}
+	if trailer := strings.TrimSpace(string(src[previousEnd:])); trailer != "" && len(file.Blocks) > 0 {
+		last := file.Blocks[len(file.Blocks)-1]
+		last.Source = strings.TrimSpace(string(src[lastStart:]))
+	}
+	return file, nil
+}
+
+// ParseBody reads a document made of attributes and blocks, such as a .tfvars file
+func ParseBody(src []byte) (*Body, error) {
+	p := &parser{src: src}
+	body := &Body{}
+	for {
+		for _, comment := range p.skipComments() {
+			body.items = append(body.items, comment)
+		}
+		if p.eof() {
+			return body, nil
+		}
+		item, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		body.items = append(body.items, item)
+	}
+}
+
+// ParseExpression reads a single expression, such as the value of an attribute
+func ParseExpression(src []byte) (Expression, error) {
+	p := &parser{src: src}
+	p.skipSpace(true)
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(true)
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q after expression", p.peek())
+	}
+	return expr, nil
+}
+
+type parser struct {
+	src []byte
+	pos int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) peekAt(offset int) byte {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line := bytes.Count(p.src[:min(p.pos, len(p.src))], []byte("\n")) + 1
+	return fmt.Errorf("hcl: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips blanks and comments, and newlines too when newlines is set
+func (p *parser) skipSpace(newlines bool) {
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+		case c == '#' || c == '/' && p.peekAt(1) == '/':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case c == '/' && p.peekAt(1) == '*':
+			end := bytes.Index(p.src[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+// skipComments skips blanks, newlines and comments like skipSpace(true) and returns the comments it skipped.
+// It is only called at the start of a line, so comments at the end of a line are not returned
+func (p *parser) skipComments() []Comment {
+	var comments []Comment
+	for !p.eof() {
+		c := p.peek()
+		start := p.pos
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case c == '#' || c == '/' && p.peekAt(1) == '/' || c == '/' && p.peekAt(1) == '*':
+			p.skipSpace(false)
+			comments = append(comments, Comment(strings.TrimSpace(string(p.src[start:p.pos]))))
+		default:
+			return comments
+		}
+	}
+	return comments
+}
+
+func isIdentifierByte(c byte, first bool) bool {
+	if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return true
+	}
+	return !first && (c == '-' || c >= '0' && c <= '9')
+}
+
+func (p *parser) parseIdentifier() (string, error) {
+	start := p.pos
+	for !p.eof() && isIdentifierByte(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected identifier, found %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+// parseItem parses an attribute or a block
+func (p *parser) parseItem() (any, error) {
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	if p.peek() == '=' && p.peekAt(1) != '=' {
+		p.pos++
+		p.skipSpace(false)
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		return &Attribute{Name: name, Value: value}, p.endOfLine()
+	}
+
+	block := NewBlock(name)
+	for p.peek() != '{' {
+		switch {
+		case p.peek() == '"':
+			label, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			block.Labels = append(block.Labels, label)
+		case isIdentifierByte(p.peek(), true):
+			label, _ := p.parseIdentifier()
+			block.Labels = append(block.Labels, label)
+		default:
+			return nil, p.errorf("expected block label or '{', found %q", p.peek())
+		}
+		p.skipSpace(false)
+	}
+	p.pos++
+	for {
+		for _, comment := range p.skipComments() {
+			block.Body.items = append(block.Body.items, comment)
+		}
+		if p.eof() {
+			return nil, p.errorf("unterminated block %q", name)
+		}
+		if p.peek() == '}' {
+			p.pos++
+			break
+		}
+		item, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		block.Body.items = append(block.Body.items, item)
+	}
+	return block, p.endOfLine()
+}
+
+// endOfLine makes sure nothing but blanks and comments follow on the current line
+func (p *parser) endOfLine() error {
+	p.skipSpace(false)
+	switch p.peek() {
+	case '\n':
+		p.pos++
+		return nil
+	case 0, '}':
+		return nil
+	}
+	return p.errorf("unexpected %q at end of line", p.peek())
+}
+
+// atTerminator reports whether the expression that was just parsed ends here
+func (p *parser) atTerminator() bool {
+	save := p.pos
+	p.skipSpace(false)
+	c := p.peek()
+	p.pos = save
+	switch c {
+	case 0, '\n', ',', ']', '}', ')', '#':
+		return true
+	case '/':
+		return p.peekAt(1) == '/' || p.peekAt(1) == '*'
+	}
+	return false
+}
+
+// parseExpression decodes literals, lists and objects and falls back to Raw for everything else
+func (p *parser) parseExpression() (Expression, error) {
+	start := p.pos
+	var (
+		expr Expression
+		err  error
+	)
+	switch c := p.peek(); {
+	case c == '"':
+		var s string
+		s, err = p.parseString()
+		expr = String(s)
+	case c == '[' && !p.startsComprehension():
+		expr, err = p.parseList()
+	case c == '{' && !p.startsComprehension():
+		expr, err = p.parseObject()
+	case c >= '0' && c <= '9' || c == '-' && p.peekAt(1) >= '0' && p.peekAt(1) <= '9':
+		expr, err = p.parseNumber()
+	case c == 't' || c == 'f':
+		word, _ := p.parseIdentifier()
+		switch word {
+		case "true":
+			expr = Bool(true)
+		case "false":
+			expr = Bool(false)
+		default:
+			expr = nil
+		}
+	}
+	if err == nil && expr != nil && p.atTerminator() {
+		return expr, nil
+	}
+	if _, ok := err.(*templateError); !ok && err != nil {
+		return nil, err
+	}
+
+	p.pos = start
+	return p.parseRaw()
+}
+
+// startsComprehension reports whether the bracket opens a for expression
+func (p *parser) startsComprehension() bool {
+	save := p.pos
+	defer func() { p.pos = save }()
+	p.pos++
+	p.skipSpace(true)
+	word, err := p.parseIdentifier()
+	return err == nil && word == "for" && (p.peek() == ' ' || p.peek() == '\t')
+}
+
+// templateError signals a quoted string holding interpolations, which is kept as Raw
+type templateError struct{}
+
+func (*templateError) Error() string { return "string template" }
+
+// parseString decodes a quoted string literal
+func (p *parser) parseString() (string, error) {
+	p.pos++
+	var builder strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch {
+		case c == '"':
+			p.pos++
+			return builder.String(), nil
+		case c == '\\':
+			escaped := p.peekAt(1)
+			p.pos += 2
+			switch escaped {
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case '"', '\\':
+				builder.WriteByte(escaped)
+			case 'u', 'U':
+				size := 4
+				if escaped == 'U' {
+					size = 8
+				}
+				if p.pos+size > len(p.src) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+size]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				builder.WriteRune(rune(code))
+				p.pos += size
+			default:
+				return "", p.errorf("invalid escape sequence \\%c", escaped)
+			}
+		case (c == '$' || c == '%') && p.peekAt(1) == c && p.peekAt(2) == '{':
+			builder.WriteByte(c)
+			builder.WriteByte('{')
+			p.pos += 3
+		case (c == '$' || c == '%') && p.peekAt(1) == '{':
+			if err := p.skipString(p.pos); err != nil {
+				return "", err
+			}
+			return "", &templateError{}
+		default:
+			r, size := utf8.DecodeRune(p.src[p.pos:])
+			builder.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+func (p *parser) parseList() (Expression, error) {
+	p.pos++
+	list := List{}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated list")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+		p.skipSpace(true)
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, p.errorf("expected ',' or ']' in list, found %q", p.peek())
+		}
+	}
+}
+
+func (p *parser) parseObject() (Expression, error) {
+	p.pos++
+	object := Object{}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated object")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return object, nil
+		}
+		var (
+			key string
+			err error
+		)
+		if p.peek() == '"' {
+			key, err = p.parseString()
+		} else {
+			key, err = p.parseIdentifier()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		if p.peek() != '=' && p.peek() != ':' {
+			return nil, p.errorf("expected '=' after object key %q", key)
+		}
+		p.pos++
+		p.skipSpace(false)
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		object = append(object, ObjectItem{Key: key, Value: value})
+		p.skipSpace(false)
+		if p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) parseNumber() (Expression, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	n, err := strconv.Atoi(string(p.src[start:p.pos]))
+	if err != nil || p.peek() == '.' || p.peek() == 'e' || p.peek() == 'E' {
+		// Not an integer literal; parseExpression keeps it as Raw
+		return nil, nil
+	}
+	return Number(n), nil
+}
+
+// parseRaw captures the source of an expression up to the end of the line, a comma or an unbalanced bracket
+func (p *parser) parseRaw() (Expression, error) {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '"':
+			if err := p.skipString(p.pos); err != nil {
+				return nil, err
+			}
+			continue
+		case c == '<' && p.peekAt(1) == '<':
+			if err := p.skipHeredoc(); err != nil {
+				return nil, err
+			}
+			continue
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return p.raw(start)
+			}
+			depth--
+		case depth == 0 && (c == '\n' || c == ',' || c == '#' || c == '/' && (p.peekAt(1) == '/' || p.peekAt(1) == '*')):
+			return p.raw(start)
+		}
+		p.pos++
+	}
+	if depth > 0 {
+		return nil, p.errorf("unbalanced brackets in expression")
+	}
+	return p.raw(start)
+}
+
+func (p *parser) raw(start int) (Expression, error) {
+	source := strings.TrimSpace(string(p.src[start:p.pos]))
+	if source == "" {
+		return nil, p.errorf("expected expression, found %q", p.peek())
+	}
+	return Raw(source), nil
+}
+
+// skipString moves past the quoted string starting at start, including any interpolations inside it
+func (p *parser) skipString(start int) error {
+	p.pos = start + 1
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch {
+		case c == '\\':
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			return nil
+		case (c == '$' || c == '%') && p.peekAt(1) == c && p.peekAt(2) == '{':
+			p.pos += 3
+		case (c == '$' || c == '%') && p.peekAt(1) == '{':
+			p.pos += 2
+			if err := p.skipInterpolation(); err != nil {
+				return err
+			}
+		default:
+			p.pos++
+		}
+	}
+}
+
+// skipInterpolation moves past the closing brace of a template interpolation
+func (p *parser) skipInterpolation() error {
+	depth := 0
+	for !p.eof() {
+		switch c := p.peek(); c {
+		case '"':
+			if err := p.skipString(p.pos); err != nil {
+				return err
+			}
+			continue
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+			depth--
+		}
+		p.pos++
+	}
+	return p.errorf("unterminated template interpolation")
+}
+
+// skipHeredoc moves past a <<EOT or <<-EOT heredoc
+func (p *parser) skipHeredoc() error {
+	p.pos += 2
+	if p.peek() == '-' {
+		p.pos++
+	}
+	marker, err := p.parseIdentifier()
+	if err != nil {
+		return err
+	}
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+	for !p.eof() {
+		p.pos++
+		lineEnd := bytes.IndexByte(p.src[p.pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(p.src) - p.pos
+		}
+		if strings.TrimSpace(string(p.src[p.pos:p.pos+lineEnd])) == marker {
+			p.pos += lineEnd
+			return nil
+		}
+		p.pos += lineEnd
+	}
+	return p.errorf("unterminated heredoc %q", marker)
+}
@@ -0,0 +1,190 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestParse tests decoding documents into the model
+func TestParse(t *testing.T) {
+	src := `# managed by hand
+terraform {
+  required_providers {
+    mgc = {
+      source = "magalucloud/mgc"
+    }
+  }
+}
+
+resource "mgc_virtual_machine_instances" "web" {
+  provider = mgc.test // inline comment
+  name     = "say \"hi\" $${literal}"
+  count    = 2
+  ratio    = 1.5
+  enabled  = true
+  network = {
+    vpc_id = mgc_network_vpcs.main.id
+    "quoted key": "value",
+  }
+  tags     = ["a", "b",]
+  greeting = "hello ${var.name}"
+  names    = [for n in var.names : upper(n)]
+  script   = <<-EOT
+    echo "}"
+  EOT
+  lifecycle { prevent_destroy = true }
+}
+/* trailing */
+`
+	file, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+	if len(file.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(file.Blocks))
+	}
+	resource := file.Blocks[1]
+	if diff := cmp.Diff([]string{"mgc_virtual_machine_instances", "web"}, resource.Labels); diff != "" {
+		t.Errorf("labels mismatch (-want +got):\n%s", diff)
+	}
+
+	want := map[string]Expression{
+		"provider": Raw("mgc.test"),
+		"name":     String(`say "hi" ${literal}`),
+		"count":    Number(2),
+		"ratio":    Raw("1.5"),
+		"enabled":  Bool(true),
+		"network": Object{
+			{Key: "vpc_id", Value: Raw("mgc_network_vpcs.main.id")},
+			{Key: "quoted key", Value: String("value")},
+		},
+		"tags":     List{String("a"), String("b")},
+		"greeting": Raw(`"hello ${var.name}"`),
+		"names":    Raw("[for n in var.names : upper(n)]"),
+		"script":   Raw("<<-EOT\n    echo \"}\"\n  EOT"),
+	}
+	for name, value := range want {
+		attr, ok := resource.Body.Attribute(name)
+		if !ok {
+			t.Errorf("attribute %s not found", name)
+			continue
+		}
+		if diff := cmp.Diff(value, attr.Value); diff != "" {
+			t.Errorf("attribute %s mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+	if blocks := resource.Body.Blocks(); len(blocks) != 1 || blocks[0].Type != "lifecycle" {
+		t.Errorf("expected a lifecycle block, got %+v", blocks)
+	}
+
+	if got := string(file.Bytes()); got != src {
+		t.Errorf("untouched blocks should be written back verbatim, got:\n%s", got)
+	}
+}
+
+// TestParse_RoundTrip tests that rendered documents parse back to the same model
+func TestParse_RoundTrip(t *testing.T) {
+	block := NewBlock("resource", "mgc_kubernetes_nodepool", "pool")
+	block.Body.
+		SetAttribute("provider", Raw("mgc.test")).
+		SetAttribute("name", String("line\nbreak \"quoted\" ${not} \\ tab\t")).
+		SetAttribute("replicas", Number(-3)).
+		SetAttribute("labels", Object{{Key: "app.kubernetes.io/name", Value: String("web")}}).
+		SetAttribute("taints", List{Object{{Key: "key", Value: String("a")}}})
+	lifecycle := block.Body.AppendBlock(NewBlock("lifecycle"))
+	lifecycle.Body.SetAttribute("prevent_destroy", Bool(true))
+
+	file, err := Parse(block.Bytes())
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+	parsed := file.Blocks[0]
+	parsed.Source = ""
+	if diff := cmp.Diff(string(block.Bytes()), string(parsed.Bytes())); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestParse_Comments tests that comments on their own line are written back when a block is rendered from its body
+func TestParse_Comments(t *testing.T) {
+	src := `# managed by hand
+/* kept
+   together */
+resource "mgc_virtual_machine_instances" "web" {
+  # the name is fixed
+  name = "web"
+  size = 2 # dropped with the line it ends
+
+  // never destroy
+  lifecycle {
+    prevent_destroy = true
+  }
+  # at the end
+}
+`
+	want := `# managed by hand
+/* kept
+   together */
+resource "mgc_virtual_machine_instances" "web" {
+  # the name is fixed
+  name = "web"
+  size = 2
+
+  // never destroy
+  lifecycle {
+    prevent_destroy = true
+  }
+
+  # at the end
+}
+`
+	file, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+	parsed := file.Blocks[0]
+	parsed.Source = ""
+	if diff := cmp.Diff(want, string(parsed.Bytes())); diff != "" {
+		t.Errorf("rendered block mismatch (-want +got):\n%s", diff)
+	}
+	if attrs := parsed.Body.Attributes(); len(attrs) != 2 {
+		t.Errorf("expected comments to stay out of Attributes, got %+v", attrs)
+	}
+}
+
+// TestParse_Errors tests that malformed documents are rejected
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"Unterminated Block":  "resource \"a\" \"b\" {\n  name = \"x\"\n",
+		"Unterminated String": "resource \"a\" \"b\" {\n  name = \"x\n}\n",
+		"Top-Level Attribute": "name = \"x\"\n",
+		"Missing Brace":       "resource \"a\" \"b\"\n",
+		"Garbage After Value": "resource \"a\" \"b\" {\n  name = \"x\" }}\n}\n",
+	}
+	for name, src := range tests {
+		if _, err := Parse([]byte(src)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestParseBody tests reading and writing top-level attributes, as in .tfvars files
+func TestParseBody(t *testing.T) {
+	body := &Body{}
+	body.
+		SetAttribute("api_key", String("secret \"key\"")).
+		SetAttribute("key_id", String("id"))
+
+	parsed, err := ParseBody(body.Bytes())
+	if err != nil {
+		t.Fatalf("ParseBody error = %v", err)
+	}
+	want := "api_key = \"secret \\\"key\\\"\"\nkey_id  = \"id\"\n"
+	if diff := cmp.Diff(want, string(parsed.Bytes())); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := ParseBody([]byte("api_key = \n")); err == nil {
+		t.Errorf("expected an error for a missing value")
+	}
+}
@@ -0,0 +1,146 @@
+package terralu
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect JSONSchema produces
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema describing the JSON form of value's type, such as JSONSchema(Stack{}).
+// Property names follow encoding/json, fields tagged validate:"required" are required unless they are also tagged
+// jsonschema:"optional", and the oneof, min and max rules become enums and bounds. Cross-field rules such as
+// required_without are only checked by Validate
+func JSONSchema(value any) ([]byte, error) {
+	t := reflect.TypeOf(value)
+	if t == nil {
+		return nil, fmt.Errorf("cannot describe a nil value")
+	}
+	builder := &schemaBuilder{defs: map[string]any{}}
+	root := builder.schema(t)
+	root["$schema"] = jsonSchemaDraft
+	if len(builder.defs) > 0 {
+		root["$defs"] = builder.defs
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaBuilder collects the definitions of the struct types met while describing a type
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+// schema describes a type; structs are described once in $defs and referenced from everywhere else
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := b.defs[t.Name()]; !ok {
+			// Reserve the name first so recursive types end
+			b.defs[t.Name()] = nil
+			b.defs[t.Name()] = b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+// structSchema describes the exported fields of a struct the way encoding/json writes them
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		property := b.schema(field.Type)
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		for _, rule := range rules {
+			if rule == "dive" {
+				// The remaining rules apply to the elements
+				break
+			}
+			if rule == "required" && field.Tag.Get("jsonschema") != "optional" {
+				required = append(required, name)
+			}
+			applyRule(property, field.Type, rule)
+		}
+		properties[name] = property
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyRule translates a validator rule into JSON Schema keywords where there is an equivalent
+func applyRule(property map[string]any, t reflect.Type, rule string) {
+	name, parameter, _ := strings.Cut(rule, "=")
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch name {
+	case "oneof":
+		values := []any{}
+		for _, value := range strings.Fields(parameter) {
+			if t.Kind() == reflect.String {
+				values = append(values, value)
+			} else if number, err := strconv.Atoi(value); err == nil {
+				values = append(values, number)
+			}
+		}
+		property["enum"] = values
+	case "min", "max":
+		bound, err := strconv.Atoi(parameter)
+		if err != nil {
+			return
+		}
+		keyword := map[reflect.Kind]string{
+			reflect.String: "Length",
+			reflect.Slice:  "Items",
+			reflect.Map:    "Properties",
+		}[t.Kind()]
+		if keyword == "" {
+			keyword = "imum"
+		}
+		property[name+keyword] = bound
+	case "lowercase":
+		property["pattern"] = "^[^A-Z]*$"
+	}
+}
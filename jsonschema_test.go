@@ -0,0 +1,70 @@
+package terralu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestJSONSchema tests describing the terralu input types as JSON Schema
+func TestJSONSchema(t *testing.T) {
+	content, err := JSONSchema(Stack{})
+	if err != nil {
+		t.Fatalf("JSONSchema error = %v", err)
+	}
+	var schema struct {
+		Schema string                    `json:"$schema"`
+		Ref    string                    `json:"$ref"`
+		Defs   map[string]map[string]any `json:"$defs"`
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("the schema is not valid JSON: %v", err)
+	}
+	if schema.Schema != jsonSchemaDraft || schema.Ref != "#/$defs/Stack" {
+		t.Errorf("unexpected schema header %q %q", schema.Schema, schema.Ref)
+	}
+
+	tests := []struct {
+		name       string
+		definition string
+		path       []string
+		want       any
+	}{
+		{"Required From Tags", "TerraluProviderInfo", []string{"required"}, []any{"alias", "region"}},
+		{"Snake Case Properties", "VirtualMachineSpec", []string{"properties", "machine_type", "type"}, "string"},
+		{"Struct Reference", "Stack", []string{"properties", "provider", "$ref"}, "#/$defs/TerraluProviderInfo"},
+		{"Slice Items", "Stack", []string{"properties", "vms", "items", "$ref"}, "#/$defs/VirtualMachineSpec"},
+		{"Map Values", "VirtualMachineSpec", []string{"properties", "instances", "additionalProperties", "$ref"}, "#/$defs/VirtualMachineOverrides"},
+		{"Closed Objects", "DatabaseInstance", []string{"additionalProperties"}, false},
+		{"Oneof Enum", "SecurityGroupRuleSchema", []string{"properties", "direction", "enum"}, []any{"ingress", "egress"}},
+		{"Minimum", "VirtualMachineSpec", []string{"properties", "count", "minimum"}, float64(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got any = schema.Defs[tt.definition]
+			for _, key := range tt.path {
+				object, _ := got.(map[string]any)
+				got = object[key]
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	content, err = JSONSchema(VirtualMachineInstance{})
+	if err != nil {
+		t.Fatalf("JSONSchema error = %v", err)
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("the schema is not valid JSON: %v", err)
+	}
+	if _, ok := schema.Defs["VirtualMachineInstance"]["properties"].(map[string]any)["RequiredFields"]; !ok {
+		t.Errorf("untagged fields should keep their Go names: %v", schema.Defs["VirtualMachineInstance"])
+	}
+
+	if _, err := JSONSchema(nil); err == nil {
+		t.Errorf("expected an error describing nil")
+	}
+}
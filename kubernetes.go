@@ -0,0 +1,70 @@
+package terralu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformKubernetesConfig generates the Terraform configuration for a Kubernetes cluster and its node pools
+func (t *TerraluImpl) GenerateTerraformKubernetesConfig(cluster *KubernetesClusterInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(cluster)
+	if err != nil {
+		return "", fmt.Errorf("error validating the kubernetes cluster instance: %w", err)
+	}
+
+	clusterResource := t.newResource("mgc_kubernetes_cluster", cluster.Name)
+	clusterResource.Body.
+		SetAttribute("name", hcl.String(cluster.Name)).
+		SetAttribute("version", hcl.String(cluster.Version))
+	if cluster.Description != "" {
+		clusterResource.Body.SetAttribute("description", hcl.String(cluster.Description))
+	}
+	if cluster.VPC != nil {
+		clusterResource.Body.SetAttribute("vpc_id", cluster.VPC.Expression())
+	}
+	blocks := []*hcl.Block{clusterResource}
+
+	for _, pool := range cluster.NodePools {
+		poolResource := t.newResource("mgc_kubernetes_nodepool", cluster.Name+"-"+pool.Name)
+		poolResource.Body.
+			SetAttribute("cluster_id", hcl.Raw("mgc_kubernetes_cluster."+resourceName(cluster.Name)+".id")).
+			SetAttribute("name", hcl.String(pool.Name)).
+			SetAttribute("flavor_name", hcl.String(pool.Flavor)).
+			SetAttribute("replicas", hcl.Number(pool.Replicas))
+		if pool.AutoScale {
+			poolResource.Body.
+				SetAttribute("min_replicas", hcl.Number(pool.MinReplicas)).
+				SetAttribute("max_replicas", hcl.Number(pool.MaxReplicas))
+		}
+		if len(pool.Labels) > 0 {
+			keys := make([]string, 0, len(pool.Labels))
+			for key := range pool.Labels {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			labels := hcl.Object{}
+			for _, key := range keys {
+				labels = append(labels, hcl.ObjectItem{Key: key, Value: hcl.String(pool.Labels[key])})
+			}
+			poolResource.Body.SetAttribute("labels", labels)
+		}
+		if len(pool.Taints) > 0 {
+			taints := hcl.List{}
+			for _, taint := range pool.Taints {
+				taints = append(taints, hcl.Object{
+					{Key: "key", Value: hcl.String(taint.Key)},
+					{Key: "value", Value: hcl.String(taint.Value)},
+					{Key: "effect", Value: hcl.String(taint.Effect)},
+				})
+			}
+			poolResource.Body.SetAttribute("taints", taints)
+		}
+		blocks = append(blocks, poolResource)
+	}
+
+	return t.write(blocks...)
+}
@@ -0,0 +1,128 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformKubernetesConfig tests the GenerateTerraformKubernetesConfig method
+func TestTerraluImpl_GenerateTerraformKubernetesConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	vpc := &VPCInstance{Name: "main"}
+	tests := []struct {
+		name    string
+		cluster *KubernetesClusterInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Cluster In Generated VPC With Node Pool",
+			cluster: &KubernetesClusterInstance{
+				Name:    "k8s",
+				Version: "v1.30.2",
+				VPC:     vpc.Reference(),
+				NodePools: []NodePoolSchema{
+					{
+						Name:        "workers",
+						Flavor:      "cloud-k8s.gp1.small",
+						Replicas:    2,
+						AutoScale:   true,
+						MinReplicas: 1,
+						MaxReplicas: 5,
+						Labels:      map[string]string{"tier": "app", "env": "prod"},
+						Taints: []TaintSchema{
+							{Key: "dedicated", Value: "app", Effect: "NoSchedule"},
+						},
+					},
+				},
+			},
+			want: `resource "mgc_kubernetes_cluster" "k8s" {
+			  provider    = mgc.test
+			  name        = "k8s"
+			  version     = "v1.30.2"
+			  vpc_id      = mgc_network_vpcs.main.id
+			}
+
+			resource "mgc_kubernetes_nodepool" "k8s-workers" {
+			  provider     = mgc.test
+			  cluster_id   = mgc_kubernetes_cluster.k8s.id
+			  name         = "workers"
+			  flavor_name  = "cloud-k8s.gp1.small"
+			  replicas     = 2
+			  min_replicas = 1
+			  max_replicas = 5
+			  labels = {
+			    env  = "prod"
+			    tier = "app"
+			  }
+			  taints = [
+			    {
+			      key    = "dedicated"
+			      value  = "app"
+			      effect = "NoSchedule"
+			    },
+			  ]
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Autoscaling Without Bounds",
+			cluster: &KubernetesClusterInstance{
+				Name:    "k8s",
+				Version: "v1.30.2",
+				NodePools: []NodePoolSchema{
+					{Name: "workers", Flavor: "cloud-k8s.gp1.small", Replicas: 2, AutoScale: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Taint Effect",
+			cluster: &KubernetesClusterInstance{
+				Name:    "k8s",
+				Version: "v1.30.2",
+				NodePools: []NodePoolSchema{
+					{
+						Name:     "workers",
+						Flavor:   "cloud-k8s.gp1.small",
+						Replicas: 1,
+						Taints:   []TaintSchema{{Key: "dedicated", Effect: "Sometimes"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Missing Version",
+			cluster: &KubernetesClusterInstance{Name: "k8s"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformKubernetesConfig(tt.cluster)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package terralu
+
+import (
+	"strings"
+
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// Layout selects how the workspace is split into files
+type Layout int
+
+const (
+	// SingleFileLayout writes every block to main.tf
+	SingleFileLayout Layout = iota
+	// MultiFileLayout writes the provider configuration to providers.tf, variables to variables.tf,
+	// outputs to outputs.tf and each service to its own file; anything else goes to main.tf
+	MultiFileLayout
+)
+
+const (
+	mainFile      = "main.tf"
+	providersFile = "providers.tf"
+	variablesFile = "variables.tf"
+	outputsFile   = "outputs.tf"
+)
+
+// serviceFiles maps resource type prefixes to the file holding them in the multi-file layout
+var serviceFiles = []struct {
+	prefix string
+	file   string
+}{
+	{"mgc_virtual_machine_", "vms.tf"},
+	{"mgc_ssh_keys", "ssh_keys.tf"},
+	{"mgc_block_storage_", "block_storage.tf"},
+	{"mgc_network_", "network.tf"},
+	{"mgc_dbaas_", "databases.tf"},
+	{"mgc_object_storage_", "object_storage.tf"},
+	{"mgc_kubernetes_", "kubernetes.tf"},
+}
+
+// layoutFiles returns every file of the multi-file layout in the order they are written
+func layoutFiles() []string {
+	files := []string{providersFile, variablesFile}
+	for _, service := range serviceFiles {
+		files = append(files, service.file)
+	}
+	return append(files, outputsFile, mainFile)
+}
+
+// WithLayout sets how the workspace is split into files, SingleFileLayout by default
+func WithLayout(layout Layout) Option {
+	return func(t *TerraluImpl) {
+		t.layout = layout
+	}
+}
+
+// fileFor returns the file a block belongs to. Blocks loaded from disk stay in the file they were read from
+func (t *TerraluImpl) fileFor(block *hcl.Block) string {
+	if t.layout == SingleFileLayout {
+		return mainFile
+	}
+	if file, ok := t.origins[block]; ok {
+		return file
+	}
+	switch block.Type {
+	case "terraform", "provider":
+		return providersFile
+	case "variable":
+		return variablesFile
+	case "output":
+		return outputsFile
+	case "resource":
+		if len(block.Labels) > 0 {
+			for _, service := range serviceFiles {
+				if strings.HasPrefix(block.Labels[0], service.prefix) {
+					return service.file
+				}
+			}
+		}
+	}
+	return mainFile
+}
+
+// renderFiles splits the workspace into its files. The provider, variable and output files are always present
+// in the multi-file layout, and so is any file written before, so emptied files are cleared on disk
+func (t *TerraluImpl) renderFiles() ([]string, map[string]*hcl.File) {
+	files := map[string]*hcl.File{}
+	for _, block := range t.file.Blocks {
+		name := t.fileFor(block)
+		if files[name] == nil {
+			files[name] = &hcl.File{}
+		}
+		files[name].AppendBlock(block)
+	}
+	if t.layout == SingleFileLayout {
+		if files[mainFile] == nil {
+			files[mainFile] = &hcl.File{}
+		}
+		return []string{mainFile}, files
+	}
+
+	var names []string
+	for _, name := range layoutFiles() {
+		required := name == providersFile || name == variablesFile || name == outputsFile || t.written[name]
+		if files[name] == nil && !required {
+			continue
+		}
+		if files[name] == nil {
+			files[name] = &hcl.File{}
+		}
+		names = append(names, name)
+	}
+	return names, files
+}
@@ -0,0 +1,93 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestMultiFileLayout tests that blocks are routed to their files and survive a reload
+func TestMultiFileLayout(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	dir := filepath.Join(t.TempDir(), "workspace")
+	tr, err := New(pInfo, WithDirectory(dir), WithLayout(MultiFileLayout))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+		t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformNetworkConfig(&VPCInstance{Name: "main"}); err != nil {
+		t.Fatalf("GenerateTerraformNetworkConfig error = %v", err)
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("error reading %s: %v", name, err)
+		}
+		return string(content)
+	}
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if diff := cmp.Diff([]string{".gitignore", "network.tf", "outputs.tf", "providers.tf", "terraform.tfvars", "variables.tf", "vms.tf"}, names); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(read("providers.tf"), `provider "mgc"`) || !strings.Contains(read("providers.tf"), "required_providers") {
+		t.Errorf("unexpected providers.tf:\n%s", read("providers.tf"))
+	}
+	if !strings.Contains(read("variables.tf"), `variable "api_key"`) {
+		t.Errorf("unexpected variables.tf:\n%s", read("variables.tf"))
+	}
+	if !strings.Contains(read("vms.tf"), `resource "mgc_virtual_machine_instances" "web"`) || strings.Contains(read("vms.tf"), "provider \"mgc\" {") {
+		t.Errorf("unexpected vms.tf:\n%s", read("vms.tf"))
+	}
+	if !strings.Contains(read("network.tf"), `resource "mgc_network_vpcs" "main"`) {
+		t.Errorf("unexpected network.tf:\n%s", read("network.tf"))
+	}
+
+	// A hand-written block stays in the file it was written to
+	custom := read("vms.tf") + "\n# hand-written\nlocals {\n  env = \"dev\"\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "vms.tf"), []byte(custom), 0644); err != nil {
+		t.Fatalf("error writing vms.tf: %v", err)
+	}
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	if loaded.GetTerraluProviderInfo().Alias != "test" {
+		t.Errorf("unexpected provider info %+v", loaded.GetTerraluProviderInfo())
+	}
+	if _, err := loaded.UpdateVirtualMachine("web", newTestVirtualMachine("web", "large")); err != nil {
+		t.Fatalf("UpdateVirtualMachine error = %v", err)
+	}
+	if !strings.Contains(read("vms.tf"), `name = "large"`) || !strings.Contains(read("vms.tf"), "# hand-written") {
+		t.Errorf("unexpected vms.tf after the update:\n%s", read("vms.tf"))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.tf")); !os.IsNotExist(err) {
+		t.Errorf("expected no main.tf, got %v", err)
+	}
+
+	// Removing the last block of a file empties it
+	if err := loaded.RemoveResource("mgc_network_vpcs", "main"); err != nil {
+		t.Fatalf("RemoveResource error = %v", err)
+	}
+	if read("network.tf") != "" {
+		t.Errorf("expected an empty network.tf, got:\n%s", read("network.tf"))
+	}
+
+	manager := NewWorkspaceManager(filepath.Dir(dir))
+	list, err := manager.List()
+	if err != nil || len(list) != 1 || list[0] != "workspace" {
+		t.Errorf("expected the workspace to be listed, got %v: %v", list, err)
+	}
+}
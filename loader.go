@@ -0,0 +1,327 @@
+package terralu
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// LoadTerralu opens a workspace directory generated earlier and parses its files.
+// A directory with a providers.tf is loaded with the multi-file layout, otherwise main.tf is read.
+// Blocks terralu does not understand are kept untouched and written back as they are
+func LoadTerralu(dir string, opts ...Option) (Terralu, error) {
+	impl := &TerraluImpl{
+		dir: dir,
+		fs:  OSFileSystem{},
+	}
+	for _, opt := range opts {
+		opt(impl)
+	}
+	dir, err := impl.directory()
+	if err != nil {
+		return nil, err
+	}
+	impl.mainPath = filepath.Join(dir, mainFile)
+
+	_, err = impl.filesystem().ReadFile(filepath.Join(dir, providersFile))
+	if err == nil {
+		impl.layout = MultiFileLayout
+	} else {
+		impl.layout = SingleFileLayout
+	}
+
+	file := &hcl.File{}
+	if impl.layout == SingleFileLayout {
+		file, err = impl.loadFile(dir, mainFile)
+		if errors.Is(err, fs.ErrNotExist) && impl.isJSONWorkspace(dir) {
+			return nil, fmt.Errorf("workspaces in the JSON format cannot be loaded")
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		impl.origins = map[*hcl.Block]string{}
+		impl.written = map[string]bool{}
+		for _, name := range layoutFiles() {
+			part, err := impl.loadFile(dir, name)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			impl.written[name] = true
+			for _, block := range part.Blocks {
+				impl.origins[block] = name
+				file.AppendBlock(block)
+			}
+		}
+	}
+
+	impl.credentials, err = decodeProviderInfo(file, impl.credentialLookup(dir))
+	if err != nil {
+		return nil, err
+	}
+	impl.file = *file
+	return impl, nil
+}
+
+// loadFile reads and parses one file of the workspace
+func (t *TerraluImpl) loadFile(dir, name string) (*hcl.File, error) {
+	path := filepath.Join(dir, name)
+	content, err := t.filesystem().ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the file: %w", err)
+	}
+	file, err := hcl.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// isJSONWorkspace reports whether the directory holds a workspace written with JSONFormat
+func (t *TerraluImpl) isJSONWorkspace(dir string) bool {
+	for _, name := range []string{mainFile, providersFile} {
+		if _, err := t.filesystem().ReadFile(filepath.Join(dir, name+".json")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ListVirtualMachines returns the virtual machines declared in the workspace
+func (t *TerraluImpl) ListVirtualMachines() ([]*VirtualMachineInstance, error) {
+	var vms []*VirtualMachineInstance
+	for _, block := range t.file.Blocks {
+		if !isResource(block, "mgc_virtual_machine_instances") {
+			continue
+		}
+		vm, err := decodeVirtualMachine(block)
+		if err != nil {
+			vm = opaqueVirtualMachine(block)
+		}
+		_, outputs := withoutOutputs(t.file.Blocks, "mgc_virtual_machine_instances."+block.Labels[1])
+		vm.OptionalFields.SkipOutputs = outputs < 0
+		vms = append(vms, vm)
+	}
+	return vms, nil
+}
+
+// Render returns the whole workspace manifest
+func (t *TerraluImpl) Render() string {
+	return string(t.encode(&t.file))
+}
+
+// isResource reports whether the block is a resource of the given type
+func isResource(block *hcl.Block, resourceType string) bool {
+	return block.Type == "resource" && len(block.Labels) == 2 && block.Labels[0] == resourceType
+}
+
+// decodeProviderInfo reads the credentials and region from the mgc provider block
+func decodeProviderInfo(file *hcl.File, lookup func(variable string) (string, error)) (*TerraluProviderInfo, error) {
+	for _, block := range file.Blocks {
+		if block.Type != "provider" || len(block.Labels) != 1 || block.Labels[0] != "mgc" {
+			continue
+		}
+		body := newAttributes(block.Body.Attributes())
+		info := &TerraluProviderInfo{}
+		var err error
+		if info.Alias, err = body.string("alias"); err != nil {
+			return nil, err
+		}
+		if info.Region, err = body.string("region"); err != nil {
+			return nil, err
+		}
+		if info.ApiKey, err = body.stringOrVariable(apiKeyVariable, lookup); err != nil {
+			return nil, err
+		}
+		if objectStorage, ok := body.object("object_storage"); ok {
+			if keyPair, ok := objectStorage.object("key_pair"); ok {
+				if info.KeyID, err = keyPair.stringOrVariable(keyIDVariable, lookup); err != nil {
+					return nil, err
+				}
+				if info.KeySecret, err = keyPair.stringOrVariable(keySecretVariable, lookup); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("no mgc provider block found")
+}
+
+// decodeVirtualMachine converts a mgc_virtual_machine_instances block back into a VirtualMachineInstance
+func decodeVirtualMachine(block *hcl.Block) (*VirtualMachineInstance, error) {
+	body := newAttributes(block.Body.Attributes())
+	vm := &VirtualMachineInstance{}
+	var err error
+	_, counted := body["count"]
+	_, forEach := body["for_each"]
+	if counted || forEach {
+		if err = decodeFleet(body, vm); err != nil {
+			return nil, fmt.Errorf("virtual machine %q: %w", block.Labels[1], err)
+		}
+	} else if vm.RequiredFields.Name, err = body.string("name"); err != nil {
+		return nil, fmt.Errorf("virtual machine %q: %w", block.Labels[1], err)
+	}
+	if vm.OptionalFields.Instances == nil {
+		machineType, err := body.nameObject("machine_type")
+		if err != nil {
+			return nil, fmt.Errorf("virtual machine %q: %w", block.Labels[1], err)
+		}
+		image, err := body.nameObject("image")
+		if err != nil {
+			return nil, fmt.Errorf("virtual machine %q: %w", block.Labels[1], err)
+		}
+		vm.RequiredFields.MachineType = &MachineTypeSchema{Name: machineType}
+		vm.RequiredFields.Image = &ImageSchema{Name: image}
+	}
+	vm.OptionalFields.NameIsPrefix = body.bool("name_is_prefix")
+
+	switch sshKey := body["ssh_key_name"].(type) {
+	case hcl.String:
+		vm.RequiredFields.SSHKeyName = string(sshKey)
+	case hcl.Raw:
+		vm.RequiredFields.SSHKey = &SSHKeySchema{Resource: strings.TrimSuffix(string(sshKey), ".name")}
+	}
+
+	if userData, ok := body["user_data"].(hcl.String); ok {
+		decoded, err := base64.StdEncoding.DecodeString(string(userData))
+		if err != nil {
+			return nil, fmt.Errorf("virtual machine %q: user_data is not base64: %w", block.Labels[1], err)
+		}
+		vm.OptionalFields.UserData = string(decoded)
+	}
+
+	if network, ok := body.object("network"); ok {
+		vm.OptionalFields.Network.AssociatePublicIP = network.bool("associate_public_ip")
+		vm.OptionalFields.Network.DeletePublicIP = network.bool("delete_public_ip")
+		if vpcID, ok := network["vpc_id"]; ok {
+			id, resource := decodeID(vpcID)
+			vm.OptionalFields.Network.VPC = &VPCSchema{ID: id, Resource: resource}
+		}
+		if networkInterface, ok := network.object("interface"); ok {
+			vm.OptionalFields.Network.Interface = &NetworkInterface{}
+			securityGroups, _ := networkInterface["security_groups"].(hcl.List)
+			for _, item := range securityGroups {
+				object, ok := item.(hcl.Object)
+				if !ok {
+					continue
+				}
+				id, resource := decodeID(objectAttributes(object)["id"])
+				vm.OptionalFields.Network.Interface.SecurityGroups = append(vm.OptionalFields.Network.Interface.SecurityGroups, SecurityGroup{ID: id, Resource: resource})
+			}
+		}
+	}
+	return vm, nil
+}
+
+// opaqueVirtualMachine describes a VM block decodeVirtualMachine cannot read with what is written as literals.
+// The name falls back to the resource label, so the VM can still be found and removed
+func opaqueVirtualMachine(block *hcl.Block) *VirtualMachineInstance {
+	body := newAttributes(block.Body.Attributes())
+	vm := &VirtualMachineInstance{OptionalFields: VirtualMachineOptionalFields{Opaque: true}}
+	vm.RequiredFields.Name, _ = body.string("name")
+	if vm.RequiredFields.Name == "" {
+		vm.RequiredFields.Name = block.Labels[1]
+	}
+	if name, err := body.nameObject("machine_type"); err == nil {
+		vm.RequiredFields.MachineType = &MachineTypeSchema{Name: name}
+	}
+	if name, err := body.nameObject("image"); err == nil {
+		vm.RequiredFields.Image = &ImageSchema{Name: name}
+	}
+	vm.RequiredFields.SSHKeyName, _ = body.string("ssh_key_name")
+	return vm
+}
+
+// decodeID splits an ID expression into a literal ID or the address of the resource it references
+func decodeID(expr hcl.Expression) (id string, resource string) {
+	switch e := expr.(type) {
+	case hcl.String:
+		return string(e), ""
+	case hcl.Raw:
+		return "", strings.TrimSuffix(string(e), ".id")
+	}
+	return "", ""
+}
+
+// attributes indexes attribute values by name
+type attributes map[string]hcl.Expression
+
+func newAttributes(attrs []*hcl.Attribute) attributes {
+	values := attributes{}
+	for _, attr := range attrs {
+		values[attr.Name] = attr.Value
+	}
+	return values
+}
+
+func objectAttributes(object hcl.Object) attributes {
+	values := attributes{}
+	for _, item := range object {
+		values[item.Key] = item.Value
+	}
+	return values
+}
+
+// string returns a string literal attribute, or an empty string if it is missing
+func (a attributes) string(name string) (string, error) {
+	value, ok := a[name]
+	if !ok {
+		return "", nil
+	}
+	s, ok := value.(hcl.String)
+	if !ok {
+		return "", fmt.Errorf("attribute %s is not a string literal", name)
+	}
+	return string(s), nil
+}
+
+// stringOrVariable returns a string literal attribute, resolving a var.<name> reference through lookup
+func (a attributes) stringOrVariable(name string, lookup func(variable string) (string, error)) (string, error) {
+	if raw, ok := a[name].(hcl.Raw); ok {
+		if variable, ok := strings.CutPrefix(string(raw), "var."); ok {
+			return lookup(variable)
+		}
+	}
+	return a.string(name)
+}
+
+// nameObject returns the literal name of an object attribute such as machine_type = { name = "..." }
+func (a attributes) nameObject(name string) (string, error) {
+	value, ok := a[name]
+	if !ok {
+		return "", fmt.Errorf("attribute %s is missing", name)
+	}
+	if _, ok := value.(hcl.Object); !ok {
+		return "", fmt.Errorf("attribute %s is not an object literal", name)
+	}
+	object, _ := a.object(name)
+	s, err := object.string("name")
+	if err != nil {
+		return "", fmt.Errorf("attribute %s: %w", name, err)
+	}
+	return s, nil
+}
+
+// bool returns a boolean literal attribute, or false if it is missing
+func (a attributes) bool(name string) bool {
+	b, _ := a[name].(hcl.Bool)
+	return bool(b)
+}
+
+// object returns an object attribute
+func (a attributes) object(name string) (attributes, bool) {
+	object, ok := a[name].(hcl.Object)
+	if !ok {
+		return nil, false
+	}
+	return objectAttributes(object), true
+}
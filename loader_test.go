@@ -0,0 +1,214 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestLoadTerralu tests that a generated workspace can be loaded back with its hand-written blocks
+func TestLoadTerralu(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:     "test",
+		Region:    "br-se1",
+		ApiKey:    "access",
+		KeyID:     "key",
+		KeySecret: "secret",
+	}
+	sg := &SecurityGroupInstance{Name: "web"}
+	key := &SSHKeyInstance{Name: "deploy", PublicKey: testPublicKey}
+	vms := []*VirtualMachineInstance{
+		{
+			RequiredFields: VirtualMachineRequiredFields{
+				Name:        "web",
+				MachineType: &MachineTypeSchema{Name: "cloud-bs1.xsmall"},
+				Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+				SSHKey:      key.Reference(),
+			},
+			OptionalFields: VirtualMachineOptionalFields{
+				NameIsPrefix: true,
+				UserData:     "#cloud-config\npackages:\n  - nginx\n",
+				Network: NetworkSchema{
+					AssociatePublicIP: true,
+					DeletePublicIP:    true,
+					VPC:               &VPCSchema{Resource: "mgc_network_vpcs.main"},
+					Interface: &NetworkInterface{
+						SecurityGroups: []SecurityGroup{sg.Reference(), {ID: "sg-12345"}},
+					},
+				},
+			},
+		},
+		{
+			RequiredFields: VirtualMachineRequiredFields{
+				Name:        "worker",
+				MachineType: &MachineTypeSchema{Name: "cloud-bs1.small"},
+				Image:       &ImageSchema{Name: "cloud-debian-12 LTS"},
+				SSHKeyName:  "existing",
+			},
+			OptionalFields: VirtualMachineOptionalFields{
+				Network: NetworkSchema{VPC: &VPCSchema{ID: "vpc-123"}},
+			},
+		},
+	}
+
+	tr := NewTerralu(pInfo)
+	dir := filepath.Dir(tr.(*TerraluImpl).mainPath)
+	defer os.RemoveAll(dir)
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("Err on GenerateTerraformGenericProviderConfig: %v", err)
+	}
+	for _, vm := range vms {
+		if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+			t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+		}
+	}
+
+	const handWritten = `
+# keep me
+resource "mgc_dns_records" "www" {
+  value = "${mgc_virtual_machine_instances.web.network_interfaces[0].ipv4}"
+}
+`
+	file, err := os.OpenFile(filepath.Join(dir, "main.tf"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("error opening main.tf: %v", err)
+	}
+	file.WriteString(handWritten)
+	file.Close()
+
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	if diff := cmp.Diff(pInfo, loaded.GetTerraluProviderInfo()); diff != "" {
+		t.Errorf("provider info mismatch (-want +got):\n%s", diff)
+	}
+	got, err := loaded.ListVirtualMachines()
+	if err != nil {
+		t.Fatalf("ListVirtualMachines error = %v", err)
+	}
+	if diff := cmp.Diff(vms, got); diff != "" {
+		t.Errorf("virtual machines mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(loaded.Render(), strings.TrimSpace(handWritten)) {
+		t.Errorf("hand-written block was lost:\n%s", loaded.Render())
+	}
+
+	if _, err := loaded.GenerateTerraformDatabaseConfig(&DatabaseInstance{
+		Name:          "orders",
+		EngineVersion: "8.0",
+		InstanceType:  "cloud-dbaas-bs1.small",
+		VolumeSize:    20,
+		User:          "admin",
+		Password:      "supersecret",
+	}); err != nil {
+		t.Fatalf("Err on GenerateTerraformDatabaseConfig: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("error reading main.tf: %v", err)
+	}
+	if string(content) != loaded.Render() {
+		t.Errorf("main.tf and Render differ:\n%s\n---\n%s", content, loaded.Render())
+	}
+}
+
+// TestLoadTerralu_Errors tests loading invalid workspaces
+func TestLoadTerralu_Errors(t *testing.T) {
+	tests := map[string]string{
+		"Missing Provider": "terraform {}\n",
+		"Invalid HCL":      "resource \"a\" {\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+				t.Fatalf("error writing main.tf: %v", err)
+			}
+			if _, err := LoadTerralu(dir); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+	if _, err := LoadTerralu(t.TempDir()); err == nil {
+		t.Errorf("expected an error for a directory without main.tf")
+	}
+}
+
+// TestTerraluImpl_ListVirtualMachines_Opaque tests listing hand-written VMs that use expressions terralu cannot decode
+func TestTerraluImpl_ListVirtualMachines_Opaque(t *testing.T) {
+	dir := t.TempDir()
+	const content = `provider "mgc" {
+  alias   = "test"
+  region  = "br-se1"
+  api_key = "access"
+}
+
+resource "mgc_virtual_machine_instances" "local_type" {
+  name         = "local-type"
+  machine_type = local.machine_type
+  image = {
+    name = "cloud-ubuntu-22.04 LTS"
+  }
+  ssh_key_name = "key"
+}
+
+resource "mgc_virtual_machine_instances" "nested" {
+  name = "nested"
+  machine_type = {
+    name = local.mt
+  }
+}
+
+resource "mgc_virtual_machine_instances" "counted" {
+  count = var.n
+  name  = "counted-${count.index}"
+}
+
+resource "mgc_virtual_machine_instances" "variable_name" {
+  name = var.n
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+		t.Fatalf("error writing main.tf: %v", err)
+	}
+	tr, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	vms, err := tr.ListVirtualMachines()
+	if err != nil {
+		t.Fatalf("ListVirtualMachines error = %v", err)
+	}
+	want := []*VirtualMachineInstance{
+		{
+			RequiredFields: VirtualMachineRequiredFields{Name: "local-type", Image: &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"}, SSHKeyName: "key"},
+			OptionalFields: VirtualMachineOptionalFields{Opaque: true, SkipOutputs: true},
+		},
+		{
+			RequiredFields: VirtualMachineRequiredFields{Name: "nested"},
+			OptionalFields: VirtualMachineOptionalFields{Opaque: true, SkipOutputs: true},
+		},
+		{
+			RequiredFields: VirtualMachineRequiredFields{Name: "counted"},
+			OptionalFields: VirtualMachineOptionalFields{Opaque: true, SkipOutputs: true},
+		},
+		{
+			RequiredFields: VirtualMachineRequiredFields{Name: "variable_name"},
+			OptionalFields: VirtualMachineOptionalFields{Opaque: true, SkipOutputs: true},
+		},
+	}
+	if diff := cmp.Diff(want, vms); diff != "" {
+		t.Errorf("virtual machines mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := tr.UpdateVirtualMachine("nested", newTestVirtualMachine("nested", "small")); err == nil || !strings.Contains(err.Error(), "machine_type") {
+		t.Errorf("expected an error naming machine_type, got %v", err)
+	}
+	if err := tr.RemoveResource("mgc_virtual_machine_instances", "variable_name"); err != nil {
+		t.Errorf("RemoveResource error = %v", err)
+	}
+}
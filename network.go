@@ -0,0 +1,67 @@
+package terralu
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformNetworkConfig generates the Terraform configuration for a VPC, its subnet pools and subnets
+func (t *TerraluImpl) GenerateTerraformNetworkConfig(vpc *VPCInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(vpc)
+	if err != nil {
+		return "", fmt.Errorf("error validating the vpc instance: %w", err)
+	}
+	pools := make(map[string]bool, len(vpc.SubnetPools))
+	for _, pool := range vpc.SubnetPools {
+		pools[pool.Name] = true
+	}
+	for _, subnet := range vpc.Subnets {
+		if !pools[subnet.SubnetPool] {
+			return "", fmt.Errorf("subnet %q references unknown subnet pool %q", subnet.Name, subnet.SubnetPool)
+		}
+	}
+
+	vpcResource := t.newResource("mgc_network_vpcs", vpc.Name)
+	vpcResource.Body.SetAttribute("name", hcl.String(vpc.Name))
+	if vpc.Description != "" {
+		vpcResource.Body.SetAttribute("description", hcl.String(vpc.Description))
+	}
+	blocks := []*hcl.Block{vpcResource}
+
+	for _, pool := range vpc.SubnetPools {
+		poolResource := t.newResource("mgc_network_subnetpools", pool.Name)
+		poolResource.Body.
+			SetAttribute("name", hcl.String(pool.Name)).
+			SetAttribute("cidr", hcl.String(pool.CIDR))
+		if pool.Description != "" {
+			poolResource.Body.SetAttribute("description", hcl.String(pool.Description))
+		}
+		blocks = append(blocks, poolResource)
+	}
+
+	for _, subnet := range vpc.Subnets {
+		ipVersion := subnet.IPVersion
+		if ipVersion == "" {
+			ipVersion = "IPv4"
+		}
+		subnetResource := t.newResource("mgc_network_vpcs_subnets", subnet.Name)
+		subnetResource.Body.
+			SetAttribute("name", hcl.String(subnet.Name)).
+			SetAttribute("vpc_id", vpc.Reference().Expression()).
+			SetAttribute("subnetpool_id", hcl.Raw("mgc_network_subnetpools."+resourceName(subnet.SubnetPool)+".id")).
+			SetAttribute("cidr_block", hcl.String(subnet.CIDRBlock)).
+			SetAttribute("ip_version", hcl.String(ipVersion))
+		if len(subnet.DNSNameservers) > 0 {
+			subnetResource.Body.SetAttribute("dns_nameservers", hcl.Strings(subnet.DNSNameservers))
+		}
+		if subnet.Description != "" {
+			subnetResource.Body.SetAttribute("description", hcl.String(subnet.Description))
+		}
+		blocks = append(blocks, subnetResource)
+	}
+
+	return t.write(blocks...)
+}
@@ -0,0 +1,142 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformNetworkConfig tests the GenerateTerraformNetworkConfig method
+func TestTerraluImpl_GenerateTerraformNetworkConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	tests := []struct {
+		name    string
+		vpc     *VPCInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "VPC Only",
+			vpc: &VPCInstance{
+				Name: "main",
+			},
+			want: `resource "mgc_network_vpcs" "main" {
+			  provider    = mgc.test
+			  name        = "main"
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "VPC With Subnet Pool And Subnet",
+			vpc: &VPCInstance{
+				Name:        "main",
+				Description: "main network",
+				SubnetPools: []SubnetPoolSchema{
+					{Name: "pool", CIDR: "172.26.0.0/16"},
+				},
+				Subnets: []SubnetSchema{
+					{
+						Name:           "public",
+						CIDRBlock:      "172.26.1.0/24",
+						SubnetPool:     "pool",
+						DNSNameservers: []string{"8.8.8.8", "1.1.1.1"},
+					},
+				},
+			},
+			want: `resource "mgc_network_vpcs" "main" {
+			  provider    = mgc.test
+			  name        = "main"
+			  description = "main network"
+			}
+
+			resource "mgc_network_subnetpools" "pool" {
+			  provider    = mgc.test
+			  name        = "pool"
+			  cidr        = "172.26.0.0/16"
+			}
+
+			resource "mgc_network_vpcs_subnets" "public" {
+			  provider        = mgc.test
+			  name            = "public"
+			  vpc_id          = mgc_network_vpcs.main.id
+			  subnetpool_id   = mgc_network_subnetpools.pool.id
+			  cidr_block      = "172.26.1.0/24"
+			  ip_version      = "IPv4"
+			  dns_nameservers = ["8.8.8.8", "1.1.1.1"]
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Subnet With Unknown Pool",
+			vpc: &VPCInstance{
+				Name: "main",
+				Subnets: []SubnetSchema{
+					{Name: "public", CIDRBlock: "172.26.1.0/24", SubnetPool: "missing"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid CIDR",
+			vpc: &VPCInstance{
+				Name: "main",
+				SubnetPools: []SubnetPoolSchema{
+					{Name: "pool", CIDR: "not-a-cidr"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformNetworkConfig(tt.vpc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTerraluImpl_GenerateTerraformVirtualMachineConfig_VPCReference tests that a VM can reference a generated VPC
+func TestTerraluImpl_GenerateTerraformVirtualMachineConfig_VPCReference(t *testing.T) {
+	vpc := &VPCInstance{Name: "main"}
+	vm := &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{
+			Name:        "web",
+			MachineType: &MachineTypeSchema{Name: "cloud-bs1.xsmall"},
+			Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+			SSHKeyName:  "key",
+		},
+		OptionalFields: VirtualMachineOptionalFields{
+			Network: NetworkSchema{VPC: vpc.Reference()},
+		},
+	}
+	tr := NewTerralu(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"})
+	defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+	got, err := tr.GenerateTerraformVirtualMachineConfig(vm)
+	if err != nil {
+		t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+	}
+	if !strings.Contains(got, "vpc_id              = mgc_network_vpcs.main.id") {
+		t.Errorf("expected vpc reference, got %v", got)
+	}
+}
@@ -0,0 +1,37 @@
+package terralu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformBucketConfig generates the Terraform configuration for an object storage bucket
+func (t *TerraluImpl) GenerateTerraformBucketConfig(bucket *BucketInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(bucket)
+	if err != nil {
+		return "", fmt.Errorf("error validating the bucket instance: %w", err)
+	}
+	if !hasObjectStorageKeys(t.credentials) {
+		return "", fmt.Errorf("object storage requires the provider key id and key secret")
+	}
+
+	resource := t.newResource("mgc_object_storage_buckets", bucket.Name)
+	resource.Body.SetAttribute("bucket", hcl.String(bucket.Name))
+	if bucket.NameIsPrefix {
+		resource.Body.SetAttribute("bucket_is_prefix", hcl.Bool(true))
+	}
+	resource.Body.SetAttribute("enable_versioning", hcl.Bool(bucket.EnableVersioning))
+	if bucket.ACL != "" {
+		resource.Body.SetAttribute(strings.ReplaceAll(bucket.ACL, "-", "_"), hcl.Bool(true))
+	}
+	if bucket.PreventDestroy {
+		lifecycle := resource.Body.AppendBlock(hcl.NewBlock("lifecycle"))
+		lifecycle.Body.SetAttribute("prevent_destroy", hcl.Bool(true))
+	}
+
+	return t.write(append([]*hcl.Block{resource}, bucketOutputs(bucket, t.credentials.Region)...)...)
+}
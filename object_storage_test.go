@@ -0,0 +1,101 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformBucketConfig tests the GenerateTerraformBucketConfig method
+func TestTerraluImpl_GenerateTerraformBucketConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:     "test",
+		Region:    "br-se1",
+		ApiKey:    "access",
+		KeyID:     "key",
+		KeySecret: "secret",
+	}
+	tests := []struct {
+		name    string
+		pInfo   *TerraluProviderInfo
+		bucket  *BucketInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "Basic Bucket",
+			pInfo:  pInfo,
+			bucket: &BucketInstance{Name: "assets", SkipOutputs: true},
+			want: `resource "mgc_object_storage_buckets" "assets" {
+			  provider          = mgc.test
+			  bucket            = "assets"
+			  enable_versioning = false
+			}`,
+			wantErr: false,
+		},
+		{
+			name:  "Bucket With Versioning, ACL And Lifecycle",
+			pInfo: pInfo,
+			bucket: &BucketInstance{
+				SkipOutputs:      true,
+				Name:             "assets",
+				NameIsPrefix:     true,
+				EnableVersioning: true,
+				ACL:              "public-read",
+				PreventDestroy:   true,
+			},
+			want: `resource "mgc_object_storage_buckets" "assets" {
+			  provider          = mgc.test
+			  bucket            = "assets"
+			  bucket_is_prefix  = true
+			  enable_versioning = true
+			  public_read = true
+
+			  lifecycle {
+			    prevent_destroy = true
+			  }
+			}`,
+			wantErr: false,
+		},
+		{
+			name:    "Invalid ACL",
+			pInfo:   pInfo,
+			bucket:  &BucketInstance{Name: "assets", ACL: "everyone"},
+			wantErr: true,
+		},
+		{
+			name:    "Uppercase Name",
+			pInfo:   pInfo,
+			bucket:  &BucketInstance{Name: "Assets"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing Key Pair",
+			pInfo:   &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"},
+			bucket:  &BucketInstance{Name: "assets"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(tt.pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformBucketConfig(tt.bucket)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
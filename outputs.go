@@ -0,0 +1,159 @@
+package terralu
+
+import (
+	"strings"
+
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// outputBlock creates an output named after the resource and the value it exposes
+func outputBlock(name, suffix, description string, value hcl.Expression) *hcl.Block {
+	output := hcl.NewBlock("output", resourceName(name)+"_"+suffix)
+	output.Body.
+		SetAttribute("description", hcl.String(description)).
+		SetAttribute("value", value)
+	return output
+}
+
+// virtualMachineOutputs exposes the id and addresses of a VM
+func virtualMachineOutputs(vm *VirtualMachineInstance) []*hcl.Block {
+	if vm.OptionalFields.SkipOutputs {
+		return nil
+	}
+	name := vm.RequiredFields.Name
+	outputs := []*hcl.Block{
+		outputBlock(name, "id", "ID of the virtual machine "+name, fleetAttribute(vm, "id")),
+		outputBlock(name, "private_ip", "Private IPv4 of the virtual machine "+name, fleetAttribute(vm, "network_interfaces[0].local_ipv4")),
+	}
+	if vm.OptionalFields.Network.AssociatePublicIP {
+		outputs = append(outputs, outputBlock(name, "public_ip", "Public IPv4 of the virtual machine "+name, fleetAttribute(vm, "network_interfaces[0].ipv4")))
+	}
+	return outputs
+}
+
+// databaseOutputs exposes the id and endpoints of a database
+func databaseOutputs(db *DatabaseInstance) []*hcl.Block {
+	if db.SkipOutputs {
+		return nil
+	}
+	address := "mgc_dbaas_instances." + resourceName(db.Name)
+	return []*hcl.Block{
+		outputBlock(db.Name, "id", "ID of the database "+db.Name, hcl.Raw(address+".id")),
+		outputBlock(db.Name, "addresses", "Endpoints of the database "+db.Name, hcl.Raw(address+".addresses")),
+	}
+}
+
+// bucketOutputs exposes the final name and the URL of a bucket in the given region
+func bucketOutputs(bucket *BucketInstance, region string) []*hcl.Block {
+	if bucket.SkipOutputs {
+		return nil
+	}
+	attribute := "bucket"
+	if bucket.NameIsPrefix {
+		attribute = "final_name"
+	}
+	reference := "mgc_object_storage_buckets." + resourceName(bucket.Name) + "." + attribute
+	return []*hcl.Block{
+		outputBlock(bucket.Name, "name", "Name of the bucket "+bucket.Name, hcl.Raw(reference)),
+		outputBlock(bucket.Name, "url", "URL of the bucket "+bucket.Name, interpolation("https://"+region+".magaluobjects.com/", reference)),
+	}
+}
+
+// publicIPOutputs exposes the address of a public IP
+func publicIPOutputs(ip *PublicIPInstance) []*hcl.Block {
+	if ip.SkipOutputs {
+		return nil
+	}
+	address := "mgc_network_public_ips." + resourceName(ip.Name)
+	return []*hcl.Block{
+		outputBlock(ip.Name, "address", "Address of the public IP "+ip.Name, hcl.Raw(address+".public_ip")),
+	}
+}
+
+// referencesResource reports whether the output reads an attribute of the resource at the address
+func referencesResource(block *hcl.Block, address string) bool {
+	if block.Type != "output" {
+		return false
+	}
+	value, ok := block.Body.Attribute("value")
+	if !ok {
+		return false
+	}
+	raw, ok := value.Value.(hcl.Raw)
+	return ok && rawReferences(string(raw), address)
+}
+
+// bodyReferences reports whether an expression anywhere in the body, nested blocks included, references the address
+func bodyReferences(body *hcl.Body, address string) bool {
+	for _, attr := range body.Attributes() {
+		if expressionReferences(attr.Value, address) {
+			return true
+		}
+	}
+	for _, block := range body.Blocks() {
+		if bodyReferences(&block.Body, address) {
+			return true
+		}
+	}
+	return false
+}
+
+func expressionReferences(expr hcl.Expression, address string) bool {
+	switch e := expr.(type) {
+	case hcl.Raw:
+		return rawReferences(string(e), address)
+	case hcl.List:
+		for _, item := range e {
+			if expressionReferences(item, address) {
+				return true
+			}
+		}
+	case hcl.Object:
+		for _, item := range e {
+			if expressionReferences(item.Value, address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rawReferences reports whether the expression text mentions the address. It must not be part of a longer name,
+// so mgc_x.web does not match mgc_x.web2 nor the data source data.mgc_x.web
+func rawReferences(raw, address string) bool {
+	for offset := 0; ; {
+		index := strings.Index(raw[offset:], address)
+		if index < 0 {
+			return false
+		}
+		start, end := offset+index, offset+index+len(address)
+		before := start == 0 || !isIdentifierChar(raw[start-1]) && raw[start-1] != '.'
+		after := end == len(raw) || !isIdentifierChar(raw[end])
+		if before && after {
+			return true
+		}
+		offset = end
+	}
+}
+
+// isIdentifierChar reports whether the character can continue an HCL identifier
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// withoutOutputs returns the blocks without the outputs of the resource at the address,
+// and the index the first removed output had, or -1
+func withoutOutputs(blocks []*hcl.Block, address string) ([]*hcl.Block, int) {
+	kept := make([]*hcl.Block, 0, len(blocks))
+	first := -1
+	for _, block := range blocks {
+		if referencesResource(block, address) {
+			if first < 0 {
+				first = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, block)
+	}
+	return kept, first
+}
@@ -0,0 +1,153 @@
+package terralu
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestOutputs tests the outputs generated for each resource
+func TestOutputs(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access", KeyID: "key", KeySecret: "secret"}
+	vm := newTestVirtualMachine("web", "small")
+	vm.OptionalFields.Network.AssociatePublicIP = true
+	tests := []struct {
+		name     string
+		generate func(tr Terralu) (string, error)
+		want     string
+	}{
+		{
+			name:     "Virtual Machine",
+			generate: func(tr Terralu) (string, error) { return tr.GenerateTerraformVirtualMachineConfig(vm) },
+			want: `output "web_id" {
+			  description = "ID of the virtual machine web"
+			  value       = mgc_virtual_machine_instances.web.id
+			}
+
+			output "web_private_ip" {
+			  description = "Private IPv4 of the virtual machine web"
+			  value       = mgc_virtual_machine_instances.web.network_interfaces[0].local_ipv4
+			}
+
+			output "web_public_ip" {
+			  description = "Public IPv4 of the virtual machine web"
+			  value       = mgc_virtual_machine_instances.web.network_interfaces[0].ipv4
+			}`,
+		},
+		{
+			name: "Database",
+			generate: func(tr Terralu) (string, error) {
+				return tr.GenerateTerraformDatabaseConfig(&DatabaseInstance{
+					Name: "orders", EngineVersion: "8.0", InstanceType: "cloud-dbaas-bs1.small", VolumeSize: 20, User: "admin", Password: "supersecret",
+				})
+			},
+			want: `output "orders_id" {
+			  description = "ID of the database orders"
+			  value       = mgc_dbaas_instances.orders.id
+			}
+
+			output "orders_addresses" {
+			  description = "Endpoints of the database orders"
+			  value       = mgc_dbaas_instances.orders.addresses
+			}`,
+		},
+		{
+			name: "Bucket With Prefix",
+			generate: func(tr Terralu) (string, error) {
+				return tr.GenerateTerraformBucketConfig(&BucketInstance{Name: "assets", NameIsPrefix: true})
+			},
+			want: `output "assets_name" {
+			  description = "Name of the bucket assets"
+			  value       = mgc_object_storage_buckets.assets.final_name
+			}
+
+			output "assets_url" {
+			  description = "URL of the bucket assets"
+			  value       = "https://br-se1.magaluobjects.com/${mgc_object_storage_buckets.assets.final_name}"
+			}`,
+		},
+		{
+			name: "Public IP",
+			generate: func(tr Terralu) (string, error) {
+				return tr.GenerateTerraformPublicIPConfig(&PublicIPInstance{Name: "dns", VPC: &VPCSchema{ID: "vpc-123"}})
+			},
+			want: `output "dns_address" {
+			  description = "Address of the public IP dns"
+			  value       = mgc_network_public_ips.dns.public_ip
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := New(pInfo, InMemory())
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			got, err := tt.generate(tr)
+			if err != nil {
+				t.Fatalf("%s error = %v", tt.name, err)
+			}
+			got = strings.TrimSpace(got[strings.Index(got, "output "):])
+			if diff := cmp.Diff(normalizeWhitespace(tt.want), normalizeWhitespace(got)); diff != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+// TestOutputs_FollowResources tests that outputs are skipped on request and follow updates and removals
+func TestOutputs_FollowResources(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	dir := filepath.Join(t.TempDir(), "workspace")
+	tr, err := New(pInfo, WithDirectory(dir))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	skipped := newTestVirtualMachine("db", "small")
+	skipped.OptionalFields.SkipOutputs = true
+	for _, vm := range []*VirtualMachineInstance{newTestVirtualMachine("web", "small"), skipped} {
+		if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+			t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+		}
+	}
+	if strings.Contains(tr.Render(), `output "db_`) {
+		t.Errorf("expected no outputs for the skipped VM:\n%s", tr.Render())
+	}
+
+	loaded, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	vms, err := loaded.ListVirtualMachines()
+	if err != nil {
+		t.Fatalf("ListVirtualMachines error = %v", err)
+	}
+	if vms[0].OptionalFields.SkipOutputs || !vms[1].OptionalFields.SkipOutputs {
+		t.Errorf("SkipOutputs should be read back from the workspace, got %v and %v", vms[0].OptionalFields.SkipOutputs, vms[1].OptionalFields.SkipOutputs)
+	}
+
+	renamed := newTestVirtualMachine("frontend", "small")
+	if _, err := loaded.UpdateVirtualMachine("web", renamed); err != nil {
+		t.Fatalf("UpdateVirtualMachine error = %v", err)
+	}
+	got := loaded.Render()
+	if strings.Contains(got, `output "web_`) || strings.Count(got, `output "frontend_`) != 2 {
+		t.Errorf("expected the outputs to follow the rename:\n%s", got)
+	}
+	if strings.Index(got, `output "frontend_id"`) > strings.Index(got, `resource "mgc_virtual_machine_instances" "db"`) {
+		t.Errorf("expected the outputs to keep their position:\n%s", got)
+	}
+
+	if err := loaded.RemoveResource("mgc_virtual_machine_instances", "frontend"); err != nil {
+		t.Fatalf("RemoveResource error = %v", err)
+	}
+	if strings.Contains(loaded.Render(), "output ") {
+		t.Errorf("expected the outputs to be removed with the VM:\n%s", loaded.Render())
+	}
+}
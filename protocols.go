@@ -3,6 +3,7 @@ package terralu
 // Terralu is an interface for managing virtual machine instances
 type Terralu interface {
 	TerraformGenerator
+	TerraluWorkspace
 	CreateDirectory() error
 	AppendOnFile() error
 }
@@ -12,6 +13,15 @@ type TerraformGenerator interface {
 	TerraluCredentialsAndRegion
 	GenerateTerraformGenericProviderConfig() (string, error)
 	TerraformVirtualMachineGenerator
+	TerraformNetworkGenerator
+	TerraformSecurityGroupGenerator
+	TerraformDatabaseGenerator
+	TerraformBlockStorageGenerator
+	TerraformObjectStorageGenerator
+	TerraformKubernetesGenerator
+	TerraformSSHKeyGenerator
+	TerraformPublicIPGenerator
+	TerraformContainerGenerator
 }
 
 // TerraformVirtualMachineGenerator defines the contract for generating Terraform configuration for virtual machines
@@ -19,6 +29,63 @@ type TerraformVirtualMachineGenerator interface {
 	GenerateTerraformVirtualMachineConfig(vm *VirtualMachineInstance) (string, error)
 }
 
+// TerraformNetworkGenerator defines the contract for generating Terraform configuration for VPCs, subnet pools and subnets
+type TerraformNetworkGenerator interface {
+	GenerateTerraformNetworkConfig(vpc *VPCInstance) (string, error)
+}
+
+// TerraformSecurityGroupGenerator defines the contract for generating Terraform configuration for security groups and their rules
+type TerraformSecurityGroupGenerator interface {
+	GenerateTerraformSecurityGroupConfig(sg *SecurityGroupInstance) (string, error)
+}
+
+// TerraformDatabaseGenerator defines the contract for generating Terraform configuration for database instances
+type TerraformDatabaseGenerator interface {
+	GenerateTerraformDatabaseConfig(db *DatabaseInstance) (string, error)
+}
+
+// TerraformBlockStorageGenerator defines the contract for generating Terraform configuration for block storage volumes
+type TerraformBlockStorageGenerator interface {
+	GenerateTerraformBlockStorageConfig(volume *BlockStorageInstance) (string, error)
+}
+
+// TerraformObjectStorageGenerator defines the contract for generating Terraform configuration for object storage buckets
+type TerraformObjectStorageGenerator interface {
+	GenerateTerraformBucketConfig(bucket *BucketInstance) (string, error)
+}
+
+// TerraformKubernetesGenerator defines the contract for generating Terraform configuration for Kubernetes clusters and node pools
+type TerraformKubernetesGenerator interface {
+	GenerateTerraformKubernetesConfig(cluster *KubernetesClusterInstance) (string, error)
+}
+
+// TerraformSSHKeyGenerator defines the contract for generating Terraform configuration for SSH keys
+type TerraformSSHKeyGenerator interface {
+	GenerateTerraformSSHKeyConfig(key *SSHKeyInstance) (string, error)
+}
+
+// TerraformPublicIPGenerator defines the contract for generating Terraform configuration for public IPs and their attachments
+type TerraformPublicIPGenerator interface {
+	GenerateTerraformPublicIPConfig(ip *PublicIPInstance) (string, error)
+	AttachPublicIP(name string, vm *VirtualMachineSchema) (string, error)
+	DetachPublicIP(name string) error
+}
+
+// TerraformContainerGenerator defines the contract for generating Terraform configuration for Docker hosts
+type TerraformContainerGenerator interface {
+	GenerateTerraformContainerConfig(container *ContainerInstance) (string, error)
+}
+
+// TerraluWorkspace gives access to the resources already declared in the workspace
+type TerraluWorkspace interface {
+	ListVirtualMachines() ([]*VirtualMachineInstance, error)
+	UpdateVirtualMachine(name string, vm *VirtualMachineInstance) (string, error)
+	RemoveResource(resourceType, name string) error
+	Render() string
+	Save() error
+	Close() error
+}
+
 // TerraluCredentialsAndRegion manages personal info for API authorization and region setting
 type TerraluCredentialsAndRegion interface {
 	GetTerraluProviderInfo() *TerraluProviderInfo
@@ -0,0 +1,77 @@
+package terralu
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformPublicIPConfig generates the Terraform configuration for a public IP and its attachment
+func (t *TerraluImpl) GenerateTerraformPublicIPConfig(ip *PublicIPInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(ip)
+	if err != nil {
+		return "", fmt.Errorf("error validating the public ip instance: %w", err)
+	}
+	if ip.VPC.ID == "" && ip.VPC.Resource == "" {
+		return "", fmt.Errorf("public ip %q has no vpc", ip.Name)
+	}
+	if ip.AttachTo != nil && ip.AttachTo.InterfaceID == "" && ip.AttachTo.Resource == "" {
+		return "", fmt.Errorf("public ip %q attachment has no network interface", ip.Name)
+	}
+
+	ipResource := t.newResource("mgc_network_public_ips", ip.Name)
+	ipResource.Body.SetAttribute("vpc_id", ip.VPC.Expression())
+	if ip.Description != "" {
+		ipResource.Body.SetAttribute("description", hcl.String(ip.Description))
+	}
+	blocks := []*hcl.Block{ipResource}
+
+	if ip.AttachTo != nil {
+		blocks = append(blocks, t.publicIPAttachment(ip.Name, ip.AttachTo))
+	}
+
+	return t.write(append(blocks, publicIPOutputs(ip)...)...)
+}
+
+// AttachPublicIP attaches the reserved public IP with the given name to a VM, replacing its current attachment.
+// Only the attachment changes, so the IP keeps its address while it moves between VMs
+func (t *TerraluImpl) AttachPublicIP(name string, vm *VirtualMachineSchema) (string, error) {
+	if t.findResource("mgc_network_public_ips", name) < 0 {
+		return "", fmt.Errorf("public ip %q not found", name)
+	}
+	if vm == nil || vm.InterfaceID == "" && vm.Resource == "" {
+		return "", fmt.Errorf("public ip %q attachment has no network interface", name)
+	}
+	attachment := t.publicIPAttachment(name, vm)
+	index := t.findResource("mgc_network_public_ips_attach", name)
+	if index < 0 {
+		return t.write(attachment)
+	}
+
+	blocks := t.file.Blocks
+	t.file.Blocks = slices.Clone(blocks)
+	t.file.Blocks[index] = attachment
+	err := t.rewriteFile()
+	if err != nil {
+		t.file.Blocks = blocks
+		return "", err
+	}
+	return string(t.encode(&hcl.File{Blocks: []*hcl.Block{attachment}})), nil
+}
+
+// DetachPublicIP removes the attachment of the reserved public IP with the given name and keeps the IP
+func (t *TerraluImpl) DetachPublicIP(name string) error {
+	return t.RemoveResource("mgc_network_public_ips_attach", name)
+}
+
+// publicIPAttachment builds the block attaching the public IP to the network interface of the VM
+func (t *TerraluImpl) publicIPAttachment(name string, vm *VirtualMachineSchema) *hcl.Block {
+	attachment := t.newResource("mgc_network_public_ips_attach", name)
+	attachment.Body.
+		SetAttribute("public_ip_id", hcl.Raw("mgc_network_public_ips."+resourceName(name)+".id")).
+		SetAttribute("interface_id", vm.InterfaceExpression())
+	return attachment
+}
@@ -0,0 +1,170 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformPublicIPConfig tests the GenerateTerraformPublicIPConfig method
+func TestTerraluImpl_GenerateTerraformPublicIPConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	vpc := &VPCInstance{Name: "main"}
+	vm := &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{Name: "web"},
+	}
+	tests := []struct {
+		name    string
+		ip      *PublicIPInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Reserved IP Without Attachment",
+			ip: &PublicIPInstance{
+				Name:        "dns",
+				Description: "stable dns entry",
+				VPC:         vpc.Reference(),
+				SkipOutputs: true,
+			},
+			want: `resource "mgc_network_public_ips" "dns" {
+			  provider    = mgc.test
+			  vpc_id      = mgc_network_vpcs.main.id
+			  description = "stable dns entry"
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Reserved IP Attached To Generated VM",
+			ip: &PublicIPInstance{
+				Name:        "dns",
+				VPC:         vpc.Reference(),
+				AttachTo:    vm.Reference(),
+				SkipOutputs: true,
+			},
+			want: `resource "mgc_network_public_ips" "dns" {
+			  provider    = mgc.test
+			  vpc_id      = mgc_network_vpcs.main.id
+			}
+
+			resource "mgc_network_public_ips_attach" "dns" {
+			  provider     = mgc.test
+			  public_ip_id = mgc_network_public_ips.dns.id
+			  interface_id = mgc_virtual_machine_instances.web.network_interfaces[0].id
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Reserved IP Attached To Existing Interface",
+			ip: &PublicIPInstance{
+				Name:        "dns",
+				VPC:         &VPCSchema{ID: "vpc-123"},
+				AttachTo:    &VirtualMachineSchema{InterfaceID: "port-123"},
+				SkipOutputs: true,
+			},
+			want: `resource "mgc_network_public_ips" "dns" {
+			  provider    = mgc.test
+			  vpc_id      = "vpc-123"
+			}
+
+			resource "mgc_network_public_ips_attach" "dns" {
+			  provider     = mgc.test
+			  public_ip_id = mgc_network_public_ips.dns.id
+			  interface_id = "port-123"
+			}`,
+			wantErr: false,
+		},
+		{
+			name:    "Missing VPC",
+			ip:      &PublicIPInstance{Name: "dns"},
+			wantErr: true,
+		},
+		{
+			name: "Attachment Without Interface",
+			ip: &PublicIPInstance{
+				Name:        "dns",
+				VPC:         vpc.Reference(),
+				AttachTo:    &VirtualMachineSchema{ID: "vm-123"},
+				SkipOutputs: true,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformPublicIPConfig(tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTerraluImpl_AttachPublicIP tests moving a reserved public IP from one VM to another
+func TestTerraluImpl_AttachPublicIP(t *testing.T) {
+	tr, err := New(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}, InMemory())
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	web := newTestVirtualMachine("web", "small")
+	api := newTestVirtualMachine("api", "small")
+	for _, vm := range []*VirtualMachineInstance{web, api} {
+		if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+			t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+		}
+	}
+	ip := &PublicIPInstance{Name: "dns", VPC: &VPCSchema{ID: "vpc-123"}, AttachTo: web.Reference()}
+	if _, err := tr.GenerateTerraformPublicIPConfig(ip); err != nil {
+		t.Fatalf("Err on GenerateTerraformPublicIPConfig: %v", err)
+	}
+
+	got, err := tr.AttachPublicIP("dns", api.Reference())
+	if err != nil {
+		t.Fatalf("AttachPublicIP error = %v", err)
+	}
+	if !strings.Contains(got, "mgc_virtual_machine_instances.api.network_interfaces[0].id") {
+		t.Errorf("expected the attachment to the api VM, got:\n%s", got)
+	}
+	manifest := tr.Render()
+	if strings.Count(manifest, `resource "mgc_network_public_ips_attach"`) != 1 || !strings.Contains(manifest, "interface_id = mgc_virtual_machine_instances.api") || strings.Contains(manifest, "interface_id = mgc_virtual_machine_instances.web") {
+		t.Errorf("expected a single attachment to the api VM:\n%s", manifest)
+	}
+	if strings.Count(manifest, `resource "mgc_network_public_ips" "dns"`) != 1 {
+		t.Errorf("the public ip should be kept:\n%s", manifest)
+	}
+
+	if err := tr.DetachPublicIP("dns"); err != nil {
+		t.Fatalf("DetachPublicIP error = %v", err)
+	}
+	if strings.Contains(tr.Render(), "mgc_network_public_ips_attach") {
+		t.Errorf("expected no attachment after DetachPublicIP:\n%s", tr.Render())
+	}
+	if _, err := tr.AttachPublicIP("dns", web.Reference()); err != nil {
+		t.Errorf("expected attaching a detached IP to succeed, got %v", err)
+	}
+
+	if _, err := tr.AttachPublicIP("missing", web.Reference()); err == nil {
+		t.Errorf("expected an error attaching a missing public ip")
+	}
+	if _, err := tr.AttachPublicIP("dns", &VirtualMachineSchema{ID: "vm-123"}); err == nil {
+		t.Errorf("expected an error attaching without a network interface")
+	}
+}
@@ -0,0 +1,207 @@
+package terralu
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// virtualMachineAttributes are the attributes virtualMachineBlock can set. An update replaces them and keeps
+// every other attribute, nested block and comment of the VM
+var virtualMachineAttributes = []string{
+	"provider", "count", "for_each", "name", "name_is_prefix", "machine_type", "image", "network", "ssh_key_name", "user_data",
+}
+
+// UpdateVirtualMachine replaces the generated attributes of the virtual machine with the given name and rewrites
+// the workspace file. Hand-written attributes, nested blocks such as lifecycle and comments are kept
+func (t *TerraluImpl) UpdateVirtualMachine(name string, vm *VirtualMachineInstance) (string, error) {
+	index := t.findResource("mgc_virtual_machine_instances", name)
+	if index < 0 {
+		return "", fmt.Errorf("virtual machine %q not found", name)
+	}
+	if _, err := decodeVirtualMachine(t.file.Blocks[index]); err != nil {
+		return "", fmt.Errorf("virtual machine %q cannot be updated, edit it by hand: %w", name, err)
+	}
+	generated, err := t.virtualMachineBlock(vm)
+	if err != nil {
+		return "", err
+	}
+	resource := mergeGenerated(t.file.Blocks[index], generated, virtualMachineAttributes)
+	if renamed := t.findResource("mgc_virtual_machine_instances", vm.RequiredFields.Name); renamed >= 0 && renamed != index {
+		return "", fmt.Errorf("virtual machine %q already exists", vm.RequiredFields.Name)
+	}
+
+	// The outputs of the VM are regenerated, in place of the previous ones if there were any
+	address := "mgc_virtual_machine_instances." + resourceName(name)
+	blocks := t.file.Blocks
+	updated := append([]*hcl.Block{}, blocks...)
+	updated[index] = resource
+	updated, position := withoutOutputs(updated, address)
+	if resourceName(vm.RequiredFields.Name) != resourceName(name) {
+		others := slices.DeleteFunc(slices.Clone(updated), func(block *hcl.Block) bool { return block == resource })
+		if dependents := referencingBlocks(others, address); len(dependents) > 0 {
+			return "", fmt.Errorf("virtual machine %q cannot be renamed while %s still reference it", name, strings.Join(dependents, ", "))
+		}
+	}
+	outputs := virtualMachineOutputs(vm)
+	if position < 0 {
+		position = len(updated)
+	}
+	updated = append(updated[:position], append(outputs, updated[position:]...)...)
+	t.file.Blocks = updated
+	err = t.rewriteFile()
+	if err != nil {
+		t.file.Blocks = blocks
+		return "", err
+	}
+	return string(t.encode(&hcl.File{Blocks: append([]*hcl.Block{resource}, outputs...)})), nil
+}
+
+// mergeGenerated returns a copy of the existing block with the managed attributes replaced by those of the generated
+// block, which are written where the first managed attribute was. Everything else keeps its place
+func mergeGenerated(existing, generated *hcl.Block, managed []string) *hcl.Block {
+	merged := hcl.NewBlock(generated.Type, generated.Labels...)
+	merged.Comments = existing.Comments
+	written := false
+	writeGenerated := func() {
+		for _, attr := range generated.Body.Attributes() {
+			merged.Body.SetAttribute(attr.Name, attr.Value)
+		}
+		written = true
+	}
+	for _, item := range existing.Body.Items() {
+		switch item := item.(type) {
+		case *hcl.Attribute:
+			if !slices.Contains(managed, item.Name) {
+				merged.Body.SetAttribute(item.Name, item.Value)
+			} else if !written {
+				writeGenerated()
+			}
+		case *hcl.Block:
+			merged.Body.AppendBlock(item)
+		case hcl.Comment:
+			merged.Body.AppendComment(item)
+		}
+	}
+	if !written {
+		writeGenerated()
+	}
+	return merged
+}
+
+// RemoveResource removes the resource of the given type and name, along with its outputs, and rewrites the workspace file.
+// It refuses while other blocks still reference the resource, since the next plan would fail
+func (t *TerraluImpl) RemoveResource(resourceType, name string) error {
+	index := t.findResource(resourceType, name)
+	if index < 0 {
+		return fmt.Errorf("resource %s.%s not found", resourceType, resourceName(name))
+	}
+	address := resourceType + "." + resourceName(name)
+	blocks := t.file.Blocks
+	kept, _ := withoutOutputs(append(append([]*hcl.Block{}, blocks[:index]...), blocks[index+1:]...), address)
+	if dependents := referencingBlocks(kept, address); len(dependents) > 0 {
+		return fmt.Errorf("resource %s is still referenced by %s, remove them first", address, strings.Join(dependents, ", "))
+	}
+	t.file.Blocks = kept
+	err := t.rewriteFile()
+	if err != nil {
+		t.file.Blocks = blocks
+		return err
+	}
+	return nil
+}
+
+// referencingBlocks returns the addresses of the blocks that reference the address
+func referencingBlocks(blocks []*hcl.Block, address string) []string {
+	var dependents []string
+	for _, block := range blocks {
+		if bodyReferences(&block.Body, address) {
+			dependents = append(dependents, blockAddress(block))
+		}
+	}
+	return dependents
+}
+
+// findResource returns the index of the resource block, or -1 if it is not declared
+func (t *TerraluImpl) findResource(resourceType, name string) int {
+	for i, block := range t.file.Blocks {
+		if isResource(block, resourceType) && block.Labels[1] == resourceName(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkAddresses rejects blocks whose address is already declared in the workspace or earlier in blocks,
+// which Terraform refuses. Names that turn into the same identifier, such as "my vm" and "my_vm", share an address
+func (t *TerraluImpl) checkAddresses(blocks []*hcl.Block) error {
+	for i, block := range blocks {
+		duplicate := sameAddress(block, blocks[:i])
+		if block.Type == "resource" && len(block.Labels) == 2 {
+			duplicate = duplicate || t.findResource(block.Labels[0], block.Labels[1]) >= 0
+		} else {
+			duplicate = duplicate || sameAddress(block, t.file.Blocks)
+		}
+		if duplicate {
+			return fmt.Errorf("%s is already declared in the workspace", blockAddress(block))
+		}
+	}
+	return nil
+}
+
+// sameAddress reports whether one of the blocks is addressed like block. Only resources, data sources, outputs,
+// variables and modules have addresses
+func sameAddress(block *hcl.Block, blocks []*hcl.Block) bool {
+	switch block.Type {
+	case "resource", "data", "output", "variable", "module":
+	default:
+		return false
+	}
+	for _, other := range blocks {
+		if other.Type == block.Type && slices.Equal(other.Labels, block.Labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockAddress returns the address Terraform uses for a block, such as mgc_network_vpcs.main or output.web_id
+func blockAddress(block *hcl.Block) string {
+	address := strings.Join(block.Labels, ".")
+	if block.Type != "resource" {
+		address = block.Type + "." + address
+	}
+	return address
+}
+
+// rewriteFile replaces the workspace file with the current document, unless the workspace lives in memory
+func (t *TerraluImpl) rewriteFile() error {
+	if t.inMemory {
+		return nil
+	}
+	return t.AppendOnFile()
+}
+
+// Save writes the whole workspace to the output sink, also when it lives in memory
+func (t *TerraluImpl) Save() error {
+	err := t.AppendOnFile()
+	if err != nil {
+		return fmt.Errorf("error saving the workspace: %w", err)
+	}
+	return nil
+}
+
+// Close saves the workspace and closes the output sink, which is when archive and writer sinks emit their files
+func (t *TerraluImpl) Close() error {
+	err := t.Save()
+	if err != nil {
+		return err
+	}
+	err = t.output().Close()
+	if err != nil {
+		return fmt.Errorf("error closing the output: %w", err)
+	}
+	return nil
+}
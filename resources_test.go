@@ -0,0 +1,275 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestVirtualMachine(name, machineType string) *VirtualMachineInstance {
+	return &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{
+			Name:        name,
+			MachineType: &MachineTypeSchema{Name: machineType},
+			Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+			SSHKeyName:  "key",
+		},
+	}
+}
+
+// TestTerraluImpl_UpdateVirtualMachine tests replacing a VM in the workspace file
+func TestTerraluImpl_UpdateVirtualMachine(t *testing.T) {
+	tr := NewTerralu(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"})
+	dir := filepath.Dir(tr.(*TerraluImpl).mainPath)
+	defer os.RemoveAll(dir)
+	for _, vm := range []*VirtualMachineInstance{newTestVirtualMachine("web", "small"), newTestVirtualMachine("db", "small")} {
+		if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+			t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+		}
+	}
+
+	got, err := tr.UpdateVirtualMachine("web", newTestVirtualMachine("web", "large"))
+	if err != nil {
+		t.Fatalf("UpdateVirtualMachine error = %v", err)
+	}
+	if !strings.Contains(got, `name = "large"`) {
+		t.Errorf("expected the updated machine type, got %v", got)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("error reading main.tf: %v", err)
+	}
+	if string(content) != tr.Render() {
+		t.Errorf("main.tf and Render differ:\n%s\n---\n%s", content, tr.Render())
+	}
+	if strings.Count(string(content), `resource "mgc_virtual_machine_instances"`) != 2 || !strings.Contains(string(content), `name = "small"`) {
+		t.Errorf("unexpected workspace content:\n%s", content)
+	}
+	if strings.Index(string(content), `"web"`) > strings.Index(string(content), `"db"`) {
+		t.Errorf("the updated VM should keep its position:\n%s", content)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only main.tf in the workspace, got %v", entries)
+	}
+
+	if _, err := tr.UpdateVirtualMachine("missing", newTestVirtualMachine("missing", "large")); err == nil {
+		t.Errorf("expected an error updating a missing VM")
+	}
+	if _, err := tr.UpdateVirtualMachine("web", newTestVirtualMachine("db", "large")); err == nil {
+		t.Errorf("expected an error renaming a VM over another one")
+	}
+	if _, err := tr.UpdateVirtualMachine("web", &VirtualMachineInstance{}); err == nil {
+		t.Errorf("expected a validation error")
+	}
+}
+
+// TestTerraluImpl_UpdateVirtualMachine_HandWritten tests that an update keeps what was written by hand in the VM
+func TestTerraluImpl_UpdateVirtualMachine_HandWritten(t *testing.T) {
+	dir := t.TempDir()
+	src := `provider "mgc" {
+  alias   = "test"
+  region  = "br-se1"
+  api_key = "access"
+}
+
+# the web server
+resource "mgc_virtual_machine_instances" "web" {
+  provider = mgc.test
+  # keep the name stable
+  name = "web"
+  machine_type = {
+    name = "small"
+  }
+  image = {
+    name = "cloud-ubuntu-22.04 LTS"
+  }
+  network = {
+    associate_public_ip = false
+  }
+  ssh_key_name = "key"
+  tags         = ["web"]
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(src), 0644); err != nil {
+		t.Fatalf("error writing main.tf: %v", err)
+	}
+	tr, err := LoadTerralu(dir)
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	vm := newTestVirtualMachine("web", "large")
+	vm.OptionalFields.SkipOutputs = true
+	if _, err := tr.UpdateVirtualMachine("web", vm); err != nil {
+		t.Fatalf("UpdateVirtualMachine error = %v", err)
+	}
+
+	want := `# the web server
+resource "mgc_virtual_machine_instances" "web" {
+  provider = mgc.test
+  name     = "web"
+  machine_type = {
+    name = "large"
+  }
+  image = {
+    name = "cloud-ubuntu-22.04 LTS"
+  }
+  network = {
+    associate_public_ip = false
+  }
+  ssh_key_name = "key"
+  # keep the name stable
+  tags = ["web"]
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+`
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("error reading main.tf: %v", err)
+	}
+	if !strings.HasSuffix(string(content), want) {
+		t.Errorf("unexpected workspace content, want it to end with:\n%s\ngot:\n%s", want, content)
+	}
+}
+
+// TestTerraluImpl_RemoveResource tests removing a resource from the workspace file
+func TestTerraluImpl_RemoveResource(t *testing.T) {
+	tr := NewTerralu(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"})
+	dir := filepath.Dir(tr.(*TerraluImpl).mainPath)
+	defer os.RemoveAll(dir)
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+		t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+	}
+	if _, err := tr.GenerateTerraformBlockStorageConfig(&BlockStorageInstance{Name: "data", Size: 10, Type: "cloud_nvme1k"}); err != nil {
+		t.Fatalf("Err on GenerateTerraformBlockStorageConfig: %v", err)
+	}
+
+	if err := tr.RemoveResource("mgc_virtual_machine_instances", "web"); err != nil {
+		t.Fatalf("RemoveResource error = %v", err)
+	}
+	vms, err := tr.ListVirtualMachines()
+	if err != nil || len(vms) != 0 {
+		t.Errorf("expected no VMs, got %v (%v)", vms, err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("error reading main.tf: %v", err)
+	}
+	if strings.Contains(string(content), "mgc_virtual_machine_instances") || !strings.Contains(string(content), "mgc_block_storage_volumes") {
+		t.Errorf("unexpected workspace content:\n%s", content)
+	}
+
+	if err := tr.RemoveResource("mgc_virtual_machine_instances", "web"); err == nil {
+		t.Errorf("expected an error removing a missing resource")
+	}
+}
+
+// TestTerraluImpl_RemoveResource_Referenced tests that a resource other blocks depend on is not removed
+func TestTerraluImpl_RemoveResource_Referenced(t *testing.T) {
+	tr, err := New(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}, InMemory())
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	vm := newTestVirtualMachine("web", "small")
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+		t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+	}
+	if _, err := tr.GenerateTerraformBlockStorageConfig(&BlockStorageInstance{Name: "data", Size: 10, Type: "cloud_nvme1k", AttachTo: vm.Reference()}); err != nil {
+		t.Fatalf("Err on GenerateTerraformBlockStorageConfig: %v", err)
+	}
+	before := tr.Render()
+
+	err = tr.RemoveResource("mgc_virtual_machine_instances", "web")
+	if err == nil || !strings.Contains(err.Error(), "mgc_block_storage_volume_attachment.data") {
+		t.Errorf("expected an error naming the attachment, got %v", err)
+	}
+	if tr.Render() != before {
+		t.Errorf("the workspace should be unchanged:\n%s", tr.Render())
+	}
+
+	if err := tr.RemoveResource("mgc_block_storage_volume_attachment", "data"); err != nil {
+		t.Fatalf("RemoveResource error = %v", err)
+	}
+	if err := tr.RemoveResource("mgc_virtual_machine_instances", "web"); err != nil {
+		t.Errorf("expected the VM to be removed once nothing references it, got %v", err)
+	}
+}
+
+// TestTerraluImpl_UpdateVirtualMachine_Referenced tests that a VM other blocks depend on is not renamed
+func TestTerraluImpl_UpdateVirtualMachine_Referenced(t *testing.T) {
+	tr, err := New(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}, InMemory())
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	vm := newTestVirtualMachine("web", "small")
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(vm); err != nil {
+		t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+	}
+	if _, err := tr.GenerateTerraformBlockStorageConfig(&BlockStorageInstance{Name: "data", Size: 10, Type: "cloud_nvme1k", AttachTo: vm.Reference()}); err != nil {
+		t.Fatalf("Err on GenerateTerraformBlockStorageConfig: %v", err)
+	}
+	before := tr.Render()
+
+	_, err = tr.UpdateVirtualMachine("web", newTestVirtualMachine("web2", "small"))
+	if err == nil || !strings.Contains(err.Error(), "mgc_block_storage_volume_attachment.data") {
+		t.Errorf("expected an error naming the attachment, got %v", err)
+	}
+	if tr.Render() != before {
+		t.Errorf("the workspace should be unchanged:\n%s", tr.Render())
+	}
+	if _, err := tr.UpdateVirtualMachine("web", newTestVirtualMachine("web", "large")); err != nil {
+		t.Errorf("expected an update keeping the name to succeed, got %v", err)
+	}
+}
+
+// TestTerraluImpl_DuplicateAddress tests that a second block with the same address is rejected
+func TestTerraluImpl_DuplicateAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		second func(TerraformGenerator) (string, error)
+	}{
+		{"Same Name", func(g TerraformGenerator) (string, error) {
+			return g.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("my vm", "large"))
+		}},
+		{"Same Identifier", func(g TerraformGenerator) (string, error) {
+			return g.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("my_vm", "large"))
+		}},
+		{"Same Output", func(g TerraformGenerator) (string, error) {
+			return g.GenerateTerraformDatabaseConfig(&DatabaseInstance{
+				Name:          "my vm",
+				EngineVersion: "8.0",
+				InstanceType:  "cloud-dbaas-bs1.small",
+				VolumeSize:    20,
+				User:          "admin",
+				Password:      "supersecret",
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := New(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}, InMemory())
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("my vm", "small")); err != nil {
+				t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+			}
+			before := tr.Render()
+			if _, err := tt.second(tr); err == nil || !strings.Contains(err.Error(), "already declared") {
+				t.Errorf("expected a duplicate address error, got %v", err)
+			}
+			if tr.Render() != before {
+				t.Errorf("the workspace should be unchanged:\n%s", tr.Render())
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package terralu
 
+import "github.com/joaogabriel01/terralu/hcl"
+
 type TerraluProviderInfo struct {
-	Alias     string `validate:"required"`
-	Region    string `validate:"required"`
-	ApiKey    string `validate:"required"`
-	KeyID     string
-	KeySecret string
+	Alias  string `json:"alias" yaml:"alias" validate:"required"`
+	Region string `json:"region" yaml:"region" validate:"required"`
+	// ApiKey is left out of stack files under review and read from MGC_API_KEY, so schemas do not require it
+	ApiKey    string `json:"api_key" yaml:"api_key" validate:"required" jsonschema:"optional"`
+	KeyID     string `json:"key_id" yaml:"key_id"`
+	KeySecret string `json:"key_secret" yaml:"key_secret"`
 }
 
 // VirtualMachineInstance represents the VM instance with required and optional fields
@@ -19,13 +22,32 @@ type VirtualMachineRequiredFields struct {
 	Name        string             `validate:"required"`
 	MachineType *MachineTypeSchema `validate:"required"`
 	Image       *ImageSchema       `validate:"required"`
-	SSHKeyName  string             `validate:"required"`
+	SSHKeyName  string             `validate:"required_without=SSHKey"`
+	SSHKey      *SSHKeySchema      `validate:"required_without=SSHKeyName"`
 }
 
 // VirtualMachineOptionalFields holds optional fields for VM creation
 type VirtualMachineOptionalFields struct {
 	NameIsPrefix bool
 	Network      NetworkSchema
+	// UserData holds an inline cloud-config; it is read from UserDataPath when empty
+	UserData     string `validate:"excluded_with=UserDataPath"`
+	UserDataPath string `validate:"omitempty,file"`
+	// SkipOutputs leaves out the id and IP outputs of the VM
+	SkipOutputs bool
+	// Count creates that many identical VMs named <name>-<index>
+	Count int `validate:"omitempty,min=1"`
+	// Instances creates one VM per key, named <name>-<key>, with optional per-instance overrides
+	Instances map[string]VirtualMachineOverrides `validate:"excluded_with=Count,dive,keys,required,hostname_rfc1123,endkeys"`
+	// Opaque is set on VMs listed from hand-written blocks whose expressions terralu cannot decode, such as
+	// machine_type = local.size. Only the name and the literal fields are filled, and the VM cannot be updated
+	Opaque bool `json:"-"`
+}
+
+// VirtualMachineOverrides holds the settings of one instance of a VM fleet; empty fields keep the fleet's values
+type VirtualMachineOverrides struct {
+	MachineType string `json:"machine_type" yaml:"machine_type"`
+	Image       string `json:"image" yaml:"image"`
 }
 
 // ImageSchema represents the nested schema for image configuration
@@ -51,13 +73,242 @@ type NetworkInterface struct {
 	SecurityGroups []SecurityGroup
 }
 
-// SecurityGroup represents a security group associated with a network interface
+// SecurityGroup represents a security group associated with a network interface.
+// Resource takes precedence over ID and holds the address of a security group generated by terralu
 type SecurityGroup struct {
-	ID string
+	ID       string
+	Resource string
 }
 
-// VPCSchema represents the VPC configuration for the network
+// Expression returns the HCL expression that resolves to the security group ID
+func (s SecurityGroup) Expression() hcl.Expression {
+	if s.Resource != "" {
+		return hcl.Raw(s.Resource + ".id")
+	}
+	return hcl.String(s.ID)
+}
+
+// VPCSchema represents the VPC configuration for the network.
+// Resource takes precedence over ID and holds the address of a VPC generated by terralu
 type VPCSchema struct {
-	ID   string
-	Name string
+	ID       string
+	Name     string
+	Resource string
+}
+
+// Expression returns the HCL expression that resolves to the VPC ID
+func (v VPCSchema) Expression() hcl.Expression {
+	if v.Resource != "" {
+		return hcl.Raw(v.Resource + ".id")
+	}
+	return hcl.String(v.ID)
+}
+
+// VPCInstance represents a VPC generated by terralu along with its subnet pools and subnets
+type VPCInstance struct {
+	Name        string             `json:"name" yaml:"name" validate:"required"`
+	Description string             `json:"description" yaml:"description"`
+	SubnetPools []SubnetPoolSchema `json:"subnet_pools" yaml:"subnet_pools" validate:"dive"`
+	Subnets     []SubnetSchema     `json:"subnets" yaml:"subnets" validate:"dive"`
+}
+
+// SubnetPoolSchema represents a pool of addresses subnets can be carved from
+type SubnetPoolSchema struct {
+	Name        string `json:"name" yaml:"name" validate:"required"`
+	CIDR        string `json:"cidr" yaml:"cidr" validate:"required,cidr"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// SubnetSchema represents a subnet created inside the VPC
+type SubnetSchema struct {
+	Name           string   `json:"name" yaml:"name" validate:"required"`
+	CIDRBlock      string   `json:"cidr_block" yaml:"cidr_block" validate:"required,cidr"`
+	SubnetPool     string   `json:"subnet_pool" yaml:"subnet_pool" validate:"required"`
+	IPVersion      string   `json:"ip_version" yaml:"ip_version" validate:"omitempty,oneof=IPv4 IPv6"`
+	DNSNameservers []string `json:"dns_nameservers" yaml:"dns_nameservers" validate:"dive,ip"`
+	Description    string   `json:"description" yaml:"description"`
+}
+
+// Reference returns a VPCSchema pointing at the generated VPC resource
+func (v *VPCInstance) Reference() *VPCSchema {
+	return &VPCSchema{
+		Name:     v.Name,
+		Resource: "mgc_network_vpcs." + resourceName(v.Name),
+	}
+}
+
+// SecurityGroupInstance represents a security group generated by terralu along with its rules
+type SecurityGroupInstance struct {
+	Name                string                    `json:"name" yaml:"name" validate:"required"`
+	Description         string                    `json:"description" yaml:"description"`
+	DisableDefaultRules bool                      `json:"disable_default_rules" yaml:"disable_default_rules"`
+	Rules               []SecurityGroupRuleSchema `json:"rules" yaml:"rules" validate:"dive"`
+}
+
+// SecurityGroupRuleSchema represents an ingress or egress rule of a security group.
+// Zero ports mean the rule applies to every port of the protocol
+type SecurityGroupRuleSchema struct {
+	// Name names the rule resource after the security group; by default the name is made from the direction,
+	// protocol, ports and CIDR, so adding or removing a rule does not rename the others
+	Name           string `json:"name" yaml:"name"`
+	Direction      string `json:"direction" yaml:"direction" validate:"required,oneof=ingress egress"`
+	Protocol       string `json:"protocol" yaml:"protocol" validate:"omitempty,oneof=tcp udp icmp icmpv6"`
+	PortRangeMin   int    `json:"port_range_min" yaml:"port_range_min" validate:"required_with=PortRangeMax,omitempty,min=1,max=65535"`
+	PortRangeMax   int    `json:"port_range_max" yaml:"port_range_max" validate:"omitempty,min=1,max=65535,gtefield=PortRangeMin"`
+	RemoteIPPrefix string `json:"remote_ip_prefix" yaml:"remote_ip_prefix" validate:"omitempty,cidr"`
+	EtherType      string `json:"ethertype" yaml:"ethertype" validate:"omitempty,oneof=IPv4 IPv6"`
+	Description    string `json:"description" yaml:"description"`
+}
+
+// Reference returns a SecurityGroup pointing at the generated security group resource
+func (s *SecurityGroupInstance) Reference() SecurityGroup {
+	return SecurityGroup{
+		Resource: "mgc_network_security_groups." + resourceName(s.Name),
+	}
+}
+
+// DatabaseInstance represents a MySQL DBaaS instance.
+// Password goes to terraform.tfvars; when it is empty Terraform reads it from TF_VAR_<PasswordVariable>
+type DatabaseInstance struct {
+	Name                string `json:"name" yaml:"name" validate:"required"`
+	EngineVersion       string `json:"engine_version" yaml:"engine_version" validate:"required"`
+	InstanceType        string `json:"instance_type" yaml:"instance_type" validate:"required"`
+	VolumeSize          int    `json:"volume_size" yaml:"volume_size" validate:"required,min=10"`
+	User                string `json:"user" yaml:"user" validate:"required"`
+	Password            string `json:"password" yaml:"password" validate:"omitempty,min=8" jsonschema:"optional"`
+	BackupRetentionDays int    `json:"backup_retention_days" yaml:"backup_retention_days" validate:"omitempty,min=1"`
+	BackupStartAt       string `json:"backup_start_at" yaml:"backup_start_at" validate:"omitempty,datetime=15:04:05"`
+	// SkipOutputs leaves out the id and address outputs of the database
+	SkipOutputs bool `json:"skip_outputs" yaml:"skip_outputs"`
+}
+
+// PasswordVariable returns the name of the Terraform variable holding the password of the database
+func (d *DatabaseInstance) PasswordVariable() string {
+	return resourceName(d.Name) + "_password"
+}
+
+// BlockStorageInstance represents a block storage volume and its optional attachment
+type BlockStorageInstance struct {
+	Name     string `validate:"required"`
+	Size     int    `validate:"required,min=1"`
+	Type     string `validate:"required"`
+	AttachTo *VirtualMachineSchema
+}
+
+// VirtualMachineSchema points at an existing virtual machine.
+// Resource takes precedence over ID and holds the address of a VM generated by terralu
+type VirtualMachineSchema struct {
+	ID          string
+	InterfaceID string
+	Resource    string
+}
+
+// Expression returns the HCL expression that resolves to the virtual machine ID
+func (v VirtualMachineSchema) Expression() hcl.Expression {
+	if v.Resource != "" {
+		return hcl.Raw(v.Resource + ".id")
+	}
+	return hcl.String(v.ID)
+}
+
+// InterfaceExpression returns the HCL expression that resolves to the primary network interface ID of the virtual machine
+func (v VirtualMachineSchema) InterfaceExpression() hcl.Expression {
+	if v.Resource != "" {
+		return hcl.Raw(v.Resource + ".network_interfaces[0].id")
+	}
+	return hcl.String(v.InterfaceID)
+}
+
+// Reference returns a VirtualMachineSchema pointing at the generated VM resource; for a fleet it points at the first instance
+func (v *VirtualMachineInstance) Reference() *VirtualMachineSchema {
+	return &VirtualMachineSchema{
+		Resource: "mgc_virtual_machine_instances." + resourceName(v.RequiredFields.Name) + v.firstInstance(),
+	}
+}
+
+// BucketInstance represents an object storage bucket
+type BucketInstance struct {
+	Name             string `json:"name" yaml:"name" validate:"required,min=3,max=63,lowercase"`
+	NameIsPrefix     bool   `json:"name_is_prefix" yaml:"name_is_prefix"`
+	EnableVersioning bool   `json:"enable_versioning" yaml:"enable_versioning"`
+	ACL              string `json:"acl" yaml:"acl" validate:"omitempty,oneof=private public-read public-read-write authenticated-read"`
+	// PreventDestroy makes terraform refuse to delete the bucket; it does not set object lifecycle rules
+	PreventDestroy bool `json:"prevent_destroy" yaml:"prevent_destroy"`
+	// SkipOutputs leaves out the name and URL outputs of the bucket
+	SkipOutputs bool `json:"skip_outputs" yaml:"skip_outputs"`
+}
+
+// KubernetesClusterInstance represents a managed Kubernetes cluster and its node pools
+type KubernetesClusterInstance struct {
+	Name        string `validate:"required"`
+	Version     string `validate:"required"`
+	Description string
+	VPC         *VPCSchema
+	NodePools   []NodePoolSchema `validate:"dive"`
+}
+
+// NodePoolSchema represents a node pool of a Kubernetes cluster.
+// MinReplicas and MaxReplicas are only used when AutoScale is enabled
+type NodePoolSchema struct {
+	Name        string `validate:"required"`
+	Flavor      string `validate:"required"`
+	Replicas    int    `validate:"required,min=1"`
+	AutoScale   bool
+	MinReplicas int               `validate:"required_if=AutoScale true,omitempty,min=1,ltefield=Replicas"`
+	MaxReplicas int               `validate:"required_if=AutoScale true,omitempty,gtefield=Replicas"`
+	Labels      map[string]string `validate:"dive,keys,required,endkeys"`
+	Taints      []TaintSchema     `validate:"dive"`
+}
+
+// TaintSchema represents a Kubernetes taint applied to every node of a pool
+type TaintSchema struct {
+	Key    string `validate:"required"`
+	Value  string
+	Effect string `validate:"required,oneof=NoSchedule PreferNoSchedule NoExecute"`
+}
+
+// SSHKeyInstance represents an SSH key uploaded to the cloud.
+// The public key is read from PublicKeyPath when PublicKey is empty
+type SSHKeyInstance struct {
+	Name          string `json:"name" yaml:"name" validate:"required"`
+	PublicKey     string `json:"public_key" yaml:"public_key" validate:"required_without=PublicKeyPath"`
+	PublicKeyPath string `json:"public_key_path" yaml:"public_key_path" validate:"required_without=PublicKey,omitempty,file"`
+}
+
+// SSHKeySchema points at an SSH key generated by terralu
+type SSHKeySchema struct {
+	Resource string `validate:"required"`
+}
+
+// Expression returns the HCL expression that resolves to the SSH key name
+func (s SSHKeySchema) Expression() hcl.Expression {
+	return hcl.Raw(s.Resource + ".name")
+}
+
+// Reference returns an SSHKeySchema pointing at the generated SSH key resource
+func (s *SSHKeyInstance) Reference() *SSHKeySchema {
+	return &SSHKeySchema{
+		Resource: "mgc_ssh_keys." + resourceName(s.Name),
+	}
+}
+
+// PublicIPInstance represents a reserved public IP that lives independently of any VM.
+// Changing AttachTo only replaces the attachment, so the address survives moving between VMs
+type PublicIPInstance struct {
+	Name        string `validate:"required"`
+	Description string
+	VPC         *VPCSchema `validate:"required"`
+	AttachTo    *VirtualMachineSchema
+	// SkipOutputs leaves out the address output of the public IP
+	SkipOutputs bool
+}
+
+// ContainerInstance represents a VM that runs a Docker stack, either a preset from the catalog or a custom docker-compose file.
+// The compose file is read from ComposePath when Compose is empty
+type ContainerInstance struct {
+	Host        VirtualMachineInstance
+	Preset      string            `validate:"required_without_all=Compose ComposePath"`
+	Compose     string            `validate:"excluded_with=Preset"`
+	ComposePath string            `validate:"excluded_with=Preset Compose,omitempty,file"`
+	Environment map[string]string `validate:"dive,keys,required,endkeys"`
 }
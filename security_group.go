@@ -0,0 +1,96 @@
+package terralu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// GenerateTerraformSecurityGroupConfig generates the Terraform configuration for a security group and its rules
+func (t *TerraluImpl) GenerateTerraformSecurityGroupConfig(sg *SecurityGroupInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(sg)
+	if err != nil {
+		return "", fmt.Errorf("error validating the security group instance: %w", err)
+	}
+
+	sgResource := t.newResource("mgc_network_security_groups", sg.Name)
+	sgResource.Body.SetAttribute("name", hcl.String(sg.Name))
+	if sg.Description != "" {
+		sgResource.Body.SetAttribute("description", hcl.String(sg.Description))
+	}
+	if sg.DisableDefaultRules {
+		sgResource.Body.SetAttribute("disable_default_rules", hcl.Bool(true))
+	}
+	blocks := []*hcl.Block{sgResource}
+
+	names := map[string]bool{}
+	for _, rule := range sg.Rules {
+		name := rule.resourceName(sg.Name)
+		if names[resourceName(name)] {
+			return "", fmt.Errorf("security group %q has two rules named %q, give one of them a name", sg.Name, name)
+		}
+		names[resourceName(name)] = true
+		etherType := rule.EtherType
+		if etherType == "" {
+			etherType = "IPv4"
+		}
+		ruleResource := t.newResource("mgc_network_security_groups_rules", name)
+		ruleResource.Body.
+			SetAttribute("security_group_id", sg.Reference().Expression()).
+			SetAttribute("direction", hcl.String(rule.Direction)).
+			SetAttribute("ethertype", hcl.String(etherType))
+		if rule.Protocol != "" {
+			ruleResource.Body.SetAttribute("protocol", hcl.String(rule.Protocol))
+		}
+		if rule.PortRangeMin != 0 {
+			portRangeMax := rule.PortRangeMax
+			if portRangeMax == 0 {
+				portRangeMax = rule.PortRangeMin
+			}
+			ruleResource.Body.
+				SetAttribute("port_range_min", hcl.Number(rule.PortRangeMin)).
+				SetAttribute("port_range_max", hcl.Number(portRangeMax))
+		}
+		if rule.RemoteIPPrefix != "" {
+			ruleResource.Body.SetAttribute("remote_ip_prefix", hcl.String(rule.RemoteIPPrefix))
+		}
+		if rule.Description != "" {
+			ruleResource.Body.SetAttribute("description", hcl.String(rule.Description))
+		}
+		blocks = append(blocks, ruleResource)
+	}
+
+	return t.write(blocks...)
+}
+
+// resourceName returns the name of the rule resource: the security group name followed by the rule name,
+// or by the settings of the rule when it has no name
+func (r *SecurityGroupRuleSchema) resourceName(group string) string {
+	if r.Name != "" {
+		return group + "-" + r.Name
+	}
+	parts := []string{group, r.Direction}
+	if r.EtherType == "IPv6" {
+		parts = append(parts, "ipv6")
+	}
+	if r.Protocol != "" {
+		parts = append(parts, r.Protocol)
+	} else {
+		parts = append(parts, "any")
+	}
+	if r.PortRangeMin != 0 {
+		ports := strconv.Itoa(r.PortRangeMin)
+		if r.PortRangeMax != 0 && r.PortRangeMax != r.PortRangeMin {
+			ports += "-" + strconv.Itoa(r.PortRangeMax)
+		}
+		parts = append(parts, ports)
+	}
+	if r.RemoteIPPrefix != "" {
+		parts = append(parts, r.RemoteIPPrefix)
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,175 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformSecurityGroupConfig tests the GenerateTerraformSecurityGroupConfig method
+func TestTerraluImpl_GenerateTerraformSecurityGroupConfig(t *testing.T) {
+	pInfo := &TerraluProviderInfo{
+		Alias:  "test",
+		Region: "br-se1",
+		ApiKey: "access",
+	}
+	tests := []struct {
+		name    string
+		sg      *SecurityGroupInstance
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Security Group With Rules",
+			sg: &SecurityGroupInstance{
+				Name:                "web",
+				DisableDefaultRules: true,
+				Rules: []SecurityGroupRuleSchema{
+					{Direction: "ingress", Protocol: "tcp", PortRangeMin: 443, RemoteIPPrefix: "0.0.0.0/0"},
+					{Direction: "egress", EtherType: "IPv6"},
+				},
+			},
+			want: `resource "mgc_network_security_groups" "web" {
+			  provider              = mgc.test
+			  name                  = "web"
+			  disable_default_rules = true
+			}
+
+			resource "mgc_network_security_groups_rules" "web-ingress-tcp-443-0_0_0_0_0" {
+			  provider          = mgc.test
+			  security_group_id = mgc_network_security_groups.web.id
+			  direction         = "ingress"
+			  ethertype         = "IPv4"
+			  protocol          = "tcp"
+			  port_range_min    = 443
+			  port_range_max    = 443
+			  remote_ip_prefix  = "0.0.0.0/0"
+			}
+
+			resource "mgc_network_security_groups_rules" "web-egress-ipv6-any" {
+			  provider          = mgc.test
+			  security_group_id = mgc_network_security_groups.web.id
+			  direction         = "egress"
+			  ethertype         = "IPv6"
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Named Rule",
+			sg: &SecurityGroupInstance{
+				Name:  "web",
+				Rules: []SecurityGroupRuleSchema{{Name: "ssh", Direction: "ingress", Protocol: "tcp", PortRangeMin: 22, PortRangeMax: 23}},
+			},
+			want: `resource "mgc_network_security_groups" "web" {
+			  provider = mgc.test
+			  name     = "web"
+			}
+
+			resource "mgc_network_security_groups_rules" "web-ssh" {
+			  provider          = mgc.test
+			  security_group_id = mgc_network_security_groups.web.id
+			  direction         = "ingress"
+			  ethertype         = "IPv4"
+			  protocol          = "tcp"
+			  port_range_min    = 22
+			  port_range_max    = 23
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Rules With The Same Name",
+			sg: &SecurityGroupInstance{
+				Name: "web",
+				Rules: []SecurityGroupRuleSchema{
+					{Direction: "ingress", Protocol: "tcp", PortRangeMin: 22},
+					{Direction: "ingress", Protocol: "tcp", PortRangeMin: 22, Description: "again"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Direction",
+			sg: &SecurityGroupInstance{
+				Name:  "web",
+				Rules: []SecurityGroupRuleSchema{{Direction: "sideways"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Inverted Port Range",
+			sg: &SecurityGroupInstance{
+				Name:  "web",
+				Rules: []SecurityGroupRuleSchema{{Direction: "ingress", PortRangeMin: 90, PortRangeMax: 80}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Port Range Max Without Min",
+			sg: &SecurityGroupInstance{
+				Name:  "web",
+				Rules: []SecurityGroupRuleSchema{{Direction: "ingress", PortRangeMax: 80}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(pInfo)
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			got, err := tr.GenerateTerraformSecurityGroupConfig(tt.sg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			normalizedGot := strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "")
+			normalizedWant := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(tt.want, "\n", ""), " ", ""), "\t", "")
+			if normalizedGot != normalizedWant {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTerraluImpl_GenerateTerraformVirtualMachineConfig_SecurityGroups tests attaching generated and existing security groups to a VM
+func TestTerraluImpl_GenerateTerraformVirtualMachineConfig_SecurityGroups(t *testing.T) {
+	sg := &SecurityGroupInstance{Name: "web"}
+	vm := &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{
+			Name:        "web",
+			MachineType: &MachineTypeSchema{Name: "cloud-bs1.xsmall"},
+			Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+			SSHKeyName:  "key",
+		},
+		OptionalFields: VirtualMachineOptionalFields{
+			Network: NetworkSchema{
+				Interface: &NetworkInterface{
+					SecurityGroups: []SecurityGroup{sg.Reference(), {ID: "sg-12345"}},
+				},
+			},
+		},
+	}
+	tr := NewTerralu(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"})
+	defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+	got, err := tr.GenerateTerraformVirtualMachineConfig(vm)
+	if err != nil {
+		t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+	}
+	want := `      security_groups = [
+        {
+          id = mgc_network_security_groups.web.id
+        },
+        {
+          id = "sg-12345"
+        },
+      ]`
+	if !strings.Contains(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
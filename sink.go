@@ -0,0 +1,269 @@
+package terralu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Sink receives the rendered workspace files. Names are relative to the workspace and use forward slashes.
+// Every call carries the whole content of the file, so sinks keep only the latest version of each name
+type Sink interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+// cleanName validates a workspace file name and returns it in its canonical form
+func cleanName(name string) (string, error) {
+	cleaned := path.Clean(filepath.ToSlash(name))
+	if name == "" || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return cleaned, nil
+}
+
+// DirectorySink writes the files into a directory, replacing them atomically
+type DirectorySink struct {
+	dir string
+	fs  FileSystem
+}
+
+// NewDirectorySink creates a sink writing into dir through fs; a nil fs writes to the local disk
+func NewDirectorySink(dir string, fs FileSystem) *DirectorySink {
+	if fs == nil {
+		fs = OSFileSystem{}
+	}
+	return &DirectorySink{dir: dir, fs: fs}
+}
+
+// WriteFile creates the parent directories if needed and replaces the file
+func (d *DirectorySink) WriteFile(name string, data []byte) error {
+	name, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	fullPath := filepath.Join(d.dir, filepath.FromSlash(name))
+	err = d.fs.MkdirAll(filepath.Dir(fullPath))
+	if err != nil {
+		return fmt.Errorf("error creating the directory: %w", err)
+	}
+	return d.fs.WriteFile(fullPath, data)
+}
+
+// Close does nothing, the files are written as they arrive
+func (d *DirectorySink) Close() error {
+	return nil
+}
+
+// writePrivateFile replaces a file only its owner may read
+func (d *DirectorySink) writePrivateFile(name string, data []byte) error {
+	name, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(d.fs, filepath.Join(d.dir, filepath.FromSlash(name)), data)
+}
+
+// readFile reads a file written to the directory
+func (d *DirectorySink) readFile(name string) ([]byte, error) {
+	name, err := cleanName(name)
+	if err != nil {
+		return nil, err
+	}
+	return d.fs.ReadFile(filepath.Join(d.dir, filepath.FromSlash(name)))
+}
+
+// MemorySink keeps the files in memory, mostly for tests and for serving them from other processes
+type MemorySink struct {
+	*MemoryFileSystem
+}
+
+// NewMemorySink creates an empty in-memory sink
+func NewMemorySink() *MemorySink {
+	return &MemorySink{MemoryFileSystem: NewMemoryFileSystem()}
+}
+
+// WriteFile stores the file content
+func (m *MemorySink) WriteFile(name string, data []byte) error {
+	name, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	return m.MemoryFileSystem.WriteFile(name, data)
+}
+
+// Close does nothing, the files stay readable
+func (m *MemorySink) Close() error {
+	return nil
+}
+
+// pendingFiles collects the latest content of each file in the order they were first written
+type pendingFiles struct {
+	names  []string
+	files  map[string][]byte
+	closed bool
+}
+
+// put stores the file content, replacing an earlier version
+func (p *pendingFiles) put(name string, data []byte) error {
+	if p.closed {
+		return fmt.Errorf("sink is closed")
+	}
+	name, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	if p.files == nil {
+		p.files = map[string][]byte{}
+	}
+	if _, ok := p.files[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// close marks the files as emitted, a sink is emitted only once
+func (p *pendingFiles) close() error {
+	if p.closed {
+		return fmt.Errorf("sink is already closed")
+	}
+	p.closed = true
+	return nil
+}
+
+// TarSink writes the files as a tar archive when it is closed
+type TarSink struct {
+	w       io.Writer
+	pending pendingFiles
+}
+
+// NewTarSink creates a sink writing a tar archive to w
+func NewTarSink(w io.Writer) *TarSink {
+	return &TarSink{w: w}
+}
+
+// WriteFile stores the file until the archive is written
+func (s *TarSink) WriteFile(name string, data []byte) error {
+	return s.pending.put(name, data)
+}
+
+// Close writes the archive; it does not close the underlying writer
+func (s *TarSink) Close() error {
+	err := s.pending.close()
+	if err != nil {
+		return err
+	}
+	archive := tar.NewWriter(s.w)
+	for _, name := range s.pending.names {
+		data := s.pending.files[name]
+		err = archive.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(data)),
+		})
+		if err != nil {
+			return fmt.Errorf("error writing the tar header of %s: %w", name, err)
+		}
+		_, err = archive.Write(data)
+		if err != nil {
+			return fmt.Errorf("error writing %s to the tar archive: %w", name, err)
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		return fmt.Errorf("error closing the tar archive: %w", err)
+	}
+	return nil
+}
+
+// ZipSink writes the files as a zip archive when it is closed
+type ZipSink struct {
+	w       io.Writer
+	pending pendingFiles
+}
+
+// NewZipSink creates a sink writing a zip archive to w
+func NewZipSink(w io.Writer) *ZipSink {
+	return &ZipSink{w: w}
+}
+
+// WriteFile stores the file until the archive is written
+func (s *ZipSink) WriteFile(name string, data []byte) error {
+	return s.pending.put(name, data)
+}
+
+// Close writes the archive; it does not close the underlying writer
+func (s *ZipSink) Close() error {
+	err := s.pending.close()
+	if err != nil {
+		return err
+	}
+	archive := zip.NewWriter(s.w)
+	for _, name := range s.pending.names {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(0644)
+		file, err := archive.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("error writing the zip header of %s: %w", name, err)
+		}
+		_, err = file.Write(s.pending.files[name])
+		if err != nil {
+			return fmt.Errorf("error writing %s to the zip archive: %w", name, err)
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		return fmt.Errorf("error closing the zip archive: %w", err)
+	}
+	return nil
+}
+
+// WriterSink prints the files to a writer such as os.Stdout when it is closed.
+// Each HCL file is preceded by a "# <name>" comment so the output stays valid HCL. JSON files get no header,
+// since JSON has no comments: a single-file workspace prints one JSON document, a split one a stream of them
+type WriterSink struct {
+	w       io.Writer
+	pending pendingFiles
+}
+
+// NewWriterSink creates a sink printing the files to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// WriteFile stores the file until the sink is closed
+func (s *WriterSink) WriteFile(name string, data []byte) error {
+	return s.pending.put(name, data)
+}
+
+// Close prints the files in the order they were first written
+func (s *WriterSink) Close() error {
+	err := s.pending.close()
+	if err != nil {
+		return err
+	}
+	for i, name := range s.pending.names {
+		header := "# " + name + "\n"
+		if strings.HasSuffix(name, ".json") {
+			header = ""
+		}
+		if i > 0 {
+			header = "\n" + header
+		}
+		_, err = io.WriteString(s.w, header)
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", name, err)
+		}
+		_, err = s.w.Write(s.pending.files[name])
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", name, err)
+		}
+	}
+	return nil
+}
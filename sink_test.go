@@ -0,0 +1,174 @@
+package terralu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestDirectorySink tests that files are written under the directory and names cannot escape it
+func TestDirectorySink(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewDirectorySink(dir, nil)
+	if err := sink.WriteFile("modules/vms.tf", []byte("content")); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "modules", "vms.tf"))
+	if err != nil || string(content) != "content" {
+		t.Errorf("unexpected content %q: %v", content, err)
+	}
+	for _, name := range []string{"", "../main.tf", "/etc/main.tf"} {
+		if err := sink.WriteFile(name, nil); err == nil {
+			t.Errorf("expected an error writing %q", name)
+		}
+	}
+}
+
+// TestMemorySink tests that the latest content of each file is kept
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	sink.WriteFile("main.tf", []byte("first"))
+	sink.WriteFile("./main.tf", []byte("second"))
+	content, err := sink.ReadFile("main.tf")
+	if err != nil || string(content) != "second" {
+		t.Errorf("unexpected content %q: %v", content, err)
+	}
+	if diff := cmp.Diff([]string{"main.tf"}, sink.Files()); diff != "" {
+		t.Errorf("Files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestArchiveSinks tests that the tar, zip and writer sinks emit the latest content once, on Close
+func TestArchiveSinks(t *testing.T) {
+	want := map[string]string{"main.tf": "second", "vms.tf": "vms"}
+	tests := []struct {
+		name string
+		new  func(w io.Writer) Sink
+		read func(t *testing.T, data []byte) map[string]string
+	}{
+		{
+			name: "tar",
+			new:  func(w io.Writer) Sink { return NewTarSink(w) },
+			read: func(t *testing.T, data []byte) map[string]string {
+				files := map[string]string{}
+				reader := tar.NewReader(bytes.NewReader(data))
+				for {
+					header, err := reader.Next()
+					if err == io.EOF {
+						return files
+					}
+					if err != nil {
+						t.Fatalf("error reading the tar archive: %v", err)
+					}
+					content, _ := io.ReadAll(reader)
+					files[header.Name] = string(content)
+				}
+			},
+		},
+		{
+			name: "zip",
+			new:  func(w io.Writer) Sink { return NewZipSink(w) },
+			read: func(t *testing.T, data []byte) map[string]string {
+				reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+				if err != nil {
+					t.Fatalf("error reading the zip archive: %v", err)
+				}
+				files := map[string]string{}
+				for _, file := range reader.File {
+					r, _ := file.Open()
+					content, _ := io.ReadAll(r)
+					r.Close()
+					files[file.Name] = string(content)
+				}
+				return files
+			},
+		},
+		{
+			name: "writer",
+			new:  func(w io.Writer) Sink { return NewWriterSink(w) },
+			read: func(t *testing.T, data []byte) map[string]string {
+				if diff := cmp.Diff("# main.tf\nsecond\n# vms.tf\nvms", string(data)); diff != "" {
+					t.Errorf("writer output mismatch (-want +got):\n%s", diff)
+				}
+				return want
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			sink := tt.new(&out)
+			sink.WriteFile("main.tf", []byte("first"))
+			sink.WriteFile("vms.tf", []byte("vms"))
+			sink.WriteFile("main.tf", []byte("second"))
+			if out.Len() != 0 {
+				t.Errorf("expected nothing written before Close, got %q", out.String())
+			}
+			if err := sink.Close(); err != nil {
+				t.Fatalf("Close error = %v", err)
+			}
+			if diff := cmp.Diff(want, tt.read(t, out.Bytes())); diff != "" {
+				t.Errorf("archive mismatch (-want +got):\n%s", diff)
+			}
+			if err := sink.WriteFile("main.tf", nil); err == nil {
+				t.Errorf("expected an error writing to a closed sink")
+			}
+			if err := sink.Close(); err == nil {
+				t.Errorf("expected an error closing the sink twice")
+			}
+		})
+	}
+}
+
+// TestWriterSink_JSON tests that JSON workspaces print JSON documents without HCL comments
+func TestWriterSink_JSON(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	for _, tt := range []struct {
+		name      string
+		layout    Layout
+		documents int
+	}{
+		{"Single File", SingleFileLayout, 1},
+		{"Multiple Files", MultiFileLayout, 4},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tr, err := New(pInfo, WithSink(NewWriterSink(&out)), WithLayout(tt.layout), WithFormat(JSONFormat))
+			if err != nil {
+				t.Fatalf("New error = %v", err)
+			}
+			if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+				t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+			}
+			if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+				t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+			}
+			if err := tr.Close(); err != nil {
+				t.Fatalf("Close error = %v", err)
+			}
+
+			if tt.layout == SingleFileLayout && !json.Valid(out.Bytes()) {
+				t.Errorf("expected a single JSON document:\n%s", out.String())
+			}
+			decoder := json.NewDecoder(&out)
+			documents := 0
+			for decoder.More() {
+				var document map[string]any
+				if err := decoder.Decode(&document); err != nil {
+					t.Fatalf("document %d is not JSON: %v", documents, err)
+				}
+				documents++
+			}
+			if documents != tt.documents {
+				t.Errorf("expected %d JSON documents, got %d", tt.documents, documents)
+			}
+		})
+	}
+}
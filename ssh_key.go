@@ -0,0 +1,55 @@
+package terralu
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
+)
+
+// sshKeyPrefixes lists the algorithms accepted for public keys
+var sshKeyPrefixes = []string{"ssh-rsa ", "ssh-ed25519 ", "ecdsa-sha2-", "sk-ssh-ed25519@openssh.com ", "sk-ecdsa-sha2-"}
+
+// GenerateTerraformSSHKeyConfig generates the Terraform configuration for an SSH key
+func (t *TerraluImpl) GenerateTerraformSSHKeyConfig(key *SSHKeyInstance) (string, error) {
+	validate := validator.New()
+	err := validate.Struct(key)
+	if err != nil {
+		return "", fmt.Errorf("error validating the ssh key instance: %w", err)
+	}
+	publicKey, err := readPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	resource := t.newResource("mgc_ssh_keys", key.Name)
+	resource.Body.
+		SetAttribute("name", hcl.String(key.Name)).
+		SetAttribute("key", hcl.String(publicKey))
+
+	return t.write(resource)
+}
+
+// readPublicKey returns the trimmed public key, loading it from disk if needed
+func readPublicKey(key *SSHKeyInstance) (string, error) {
+	publicKey := key.PublicKey
+	if publicKey == "" {
+		content, err := os.ReadFile(key.PublicKeyPath)
+		if err != nil {
+			return "", fmt.Errorf("error reading the public key file: %w", err)
+		}
+		publicKey = string(content)
+	}
+	publicKey = strings.TrimSpace(publicKey)
+	if strings.ContainsAny(publicKey, "\r\n") {
+		return "", fmt.Errorf("public key must be a single line")
+	}
+	for _, prefix := range sshKeyPrefixes {
+		if strings.HasPrefix(publicKey, prefix) {
+			return publicKey, nil
+		}
+	}
+	return "", fmt.Errorf("public key is not in OpenSSH format")
+}
@@ -0,0 +1,208 @@
+package terralu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Stack is a declarative description of a whole workspace, written as YAML or JSON.
+// Resources refer to each other by name; a name that is not declared in the stack is used as the ID of an existing resource
+type Stack struct {
+	Provider       TerraluProviderInfo     `json:"provider" yaml:"provider"`
+	SSHKeys        []SSHKeyInstance        `json:"ssh_keys" yaml:"ssh_keys" validate:"unique=Name,dive"`
+	Networks       []VPCInstance           `json:"networks" yaml:"networks" validate:"unique=Name,dive"`
+	SecurityGroups []SecurityGroupInstance `json:"security_groups" yaml:"security_groups" validate:"unique=Name,dive"`
+	VMs            []VirtualMachineSpec    `json:"vms" yaml:"vms" validate:"unique=Name,dive"`
+	Databases      []DatabaseInstance      `json:"databases" yaml:"databases" validate:"unique=Name,dive"`
+	Buckets        []BucketInstance        `json:"buckets" yaml:"buckets" validate:"unique=Name,dive"`
+}
+
+// VirtualMachineSpec is the flat form of a VM in a stack
+type VirtualMachineSpec struct {
+	Name        string `json:"name" yaml:"name" validate:"required"`
+	MachineType string `json:"machine_type" yaml:"machine_type" validate:"required"`
+	Image       string `json:"image" yaml:"image" validate:"required"`
+	// SSHKey names a key of the stack or one already registered in Magalu Cloud
+	SSHKey string `json:"ssh_key" yaml:"ssh_key" validate:"required"`
+	// Network names a network of the stack or holds the ID of an existing VPC
+	Network string `json:"network" yaml:"network"`
+	// SecurityGroups name security groups of the stack or hold the IDs of existing ones
+	SecurityGroups []string                           `json:"security_groups" yaml:"security_groups" validate:"dive,required"`
+	PublicIP       bool                               `json:"public_ip" yaml:"public_ip"`
+	NameIsPrefix   bool                               `json:"name_is_prefix" yaml:"name_is_prefix"`
+	UserData       string                             `json:"user_data" yaml:"user_data" validate:"excluded_with=UserDataPath"`
+	UserDataPath   string                             `json:"user_data_path" yaml:"user_data_path" validate:"omitempty,file"`
+	Count          int                                `json:"count" yaml:"count" validate:"omitempty,min=1"`
+	Instances      map[string]VirtualMachineOverrides `json:"instances" yaml:"instances" validate:"excluded_with=Count,dive,keys,required,hostname_rfc1123,endkeys"`
+	SkipOutputs    bool                               `json:"skip_outputs" yaml:"skip_outputs"`
+}
+
+// LoadStack reads a stack file; the format follows the extension, .json or .yaml/.yml.
+// Relative file paths in the stack are resolved against the directory of the file
+func LoadStack(path string) (*Stack, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the stack file: %w", err)
+	}
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = "json"
+	case ".yaml", ".yml":
+		format = "yaml"
+	default:
+		return nil, fmt.Errorf("unknown stack format %q, use .json, .yaml or .yml", filepath.Ext(path))
+	}
+	stack, err := ParseStack(content, format)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	stack.resolvePaths(filepath.Dir(path))
+	return stack, nil
+}
+
+// ParseStack decodes a stack in the "json" or "yaml" format; unknown fields are rejected so typos do not go unnoticed
+func ParseStack(content []byte, format string) (*Stack, error) {
+	stack := &Stack{}
+	var err error
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(stack)
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(stack)
+	default:
+		return nil, fmt.Errorf("unknown stack format %q", format)
+	}
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the stack is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stack, nil
+}
+
+// resolvePaths makes the relative file paths of the stack relative to dir
+func (s *Stack) resolvePaths(dir string) {
+	resolve := func(path *string) {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+	for i := range s.SSHKeys {
+		resolve(&s.SSHKeys[i].PublicKeyPath)
+	}
+	for i := range s.VMs {
+		resolve(&s.VMs[i].UserDataPath)
+	}
+}
+
+// Validate checks the whole stack, including the provider information
+func (s *Stack) Validate() error {
+	err := validator.New().Struct(s)
+	if err != nil {
+		return fmt.Errorf("error validating the stack: %w", err)
+	}
+	return nil
+}
+
+// Compile generates every resource of the stack into the workspace, which must already hold the provider configuration.
+// Resources are generated in dependency order: SSH keys, networks, security groups, VMs, databases and buckets
+func (s *Stack) Compile(workspace TerraformGenerator) error {
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+	for i := range s.SSHKeys {
+		if _, err := workspace.GenerateTerraformSSHKeyConfig(&s.SSHKeys[i]); err != nil {
+			return fmt.Errorf("ssh key %q: %w", s.SSHKeys[i].Name, err)
+		}
+	}
+	for i := range s.Networks {
+		if _, err := workspace.GenerateTerraformNetworkConfig(&s.Networks[i]); err != nil {
+			return fmt.Errorf("network %q: %w", s.Networks[i].Name, err)
+		}
+	}
+	for i := range s.SecurityGroups {
+		if _, err := workspace.GenerateTerraformSecurityGroupConfig(&s.SecurityGroups[i]); err != nil {
+			return fmt.Errorf("security group %q: %w", s.SecurityGroups[i].Name, err)
+		}
+	}
+	for _, spec := range s.VMs {
+		if _, err := workspace.GenerateTerraformVirtualMachineConfig(s.virtualMachine(spec)); err != nil {
+			return fmt.Errorf("vm %q: %w", spec.Name, err)
+		}
+	}
+	for i := range s.Databases {
+		if _, err := workspace.GenerateTerraformDatabaseConfig(&s.Databases[i]); err != nil {
+			return fmt.Errorf("database %q: %w", s.Databases[i].Name, err)
+		}
+	}
+	for i := range s.Buckets {
+		if _, err := workspace.GenerateTerraformBucketConfig(&s.Buckets[i]); err != nil {
+			return fmt.Errorf("bucket %q: %w", s.Buckets[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// virtualMachine converts a VM spec into a VirtualMachineInstance, resolving the names it refers to
+func (s *Stack) virtualMachine(spec VirtualMachineSpec) *VirtualMachineInstance {
+	vm := &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{
+			Name:        spec.Name,
+			MachineType: &MachineTypeSchema{Name: spec.MachineType},
+			Image:       &ImageSchema{Name: spec.Image},
+			SSHKeyName:  spec.SSHKey,
+		},
+		OptionalFields: VirtualMachineOptionalFields{
+			NameIsPrefix: spec.NameIsPrefix,
+			Network:      NetworkSchema{AssociatePublicIP: spec.PublicIP},
+			UserData:     spec.UserData,
+			UserDataPath: spec.UserDataPath,
+			SkipOutputs:  spec.SkipOutputs,
+			Count:        spec.Count,
+			Instances:    spec.Instances,
+		},
+	}
+	for i := range s.SSHKeys {
+		if s.SSHKeys[i].Name == spec.SSHKey {
+			vm.RequiredFields.SSHKeyName = ""
+			vm.RequiredFields.SSHKey = s.SSHKeys[i].Reference()
+		}
+	}
+	if spec.Network != "" {
+		vm.OptionalFields.Network.VPC = &VPCSchema{ID: spec.Network}
+		for i := range s.Networks {
+			if s.Networks[i].Name == spec.Network {
+				vm.OptionalFields.Network.VPC = s.Networks[i].Reference()
+			}
+		}
+	}
+	if len(spec.SecurityGroups) > 0 {
+		vm.OptionalFields.Network.Interface = &NetworkInterface{}
+		for _, name := range spec.SecurityGroups {
+			group := SecurityGroup{ID: name}
+			for i := range s.SecurityGroups {
+				if s.SecurityGroups[i].Name == name {
+					group = s.SecurityGroups[i].Reference()
+				}
+			}
+			vm.OptionalFields.Network.Interface.SecurityGroups = append(vm.OptionalFields.Network.Interface.SecurityGroups, group)
+		}
+	}
+	return vm
+}
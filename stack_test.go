@@ -0,0 +1,194 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testStackYAML = `provider:
+  alias: mgc
+  region: br-se1
+  api_key: access
+  key_id: key
+  key_secret: secret
+ssh_keys:
+  - name: deploy
+    public_key_path: deploy.pub
+networks:
+  - name: main
+    subnet_pools:
+      - name: pool
+        cidr: 172.26.0.0/16
+security_groups:
+  - name: web
+    rules:
+      - direction: ingress
+        protocol: tcp
+        port_range_min: 443
+        port_range_max: 443
+vms:
+  - name: web
+    machine_type: BV1-1-10
+    image: cloud-ubuntu-22.04 LTS
+    ssh_key: deploy
+    network: main
+    security_groups: [web, sg-existing]
+    count: 2
+  - name: legacy
+    machine_type: BV1-1-10
+    image: cloud-ubuntu-22.04 LTS
+    ssh_key: registered-key
+    network: vpc-existing
+databases:
+  - name: orders
+    engine_version: "8.0"
+    instance_type: cloud-dbaas-bs1.small
+    volume_size: 20
+    user: admin
+    password: supersecret
+buckets:
+  - name: assets
+`
+
+const testStackJSON = `{
+  "provider": {"alias": "mgc", "region": "br-se1", "api_key": "access", "key_id": "key", "key_secret": "secret"},
+  "ssh_keys": [{"name": "deploy", "public_key_path": "deploy.pub"}],
+  "networks": [{"name": "main", "subnet_pools": [{"name": "pool", "cidr": "172.26.0.0/16"}]}],
+  "security_groups": [{"name": "web", "rules": [{"direction": "ingress", "protocol": "tcp", "port_range_min": 443, "port_range_max": 443}]}],
+  "vms": [
+    {"name": "web", "machine_type": "BV1-1-10", "image": "cloud-ubuntu-22.04 LTS", "ssh_key": "deploy", "network": "main", "security_groups": ["web", "sg-existing"], "count": 2},
+    {"name": "legacy", "machine_type": "BV1-1-10", "image": "cloud-ubuntu-22.04 LTS", "ssh_key": "registered-key", "network": "vpc-existing"}
+  ],
+  "databases": [{"name": "orders", "engine_version": "8.0", "instance_type": "cloud-dbaas-bs1.small", "volume_size": 20, "user": "admin", "password": "supersecret"}],
+  "buckets": [{"name": "assets"}]
+}`
+
+// writeTestStack writes the stack file and the public key it refers to into a temporary directory
+func writeTestStack(t *testing.T, name, content string) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "deploy.pub"), []byte(testPublicKey), 0644); err != nil {
+		t.Fatalf("error writing the public key: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing the stack: %v", err)
+	}
+	return path
+}
+
+// TestLoadStack tests that the YAML and JSON forms describe the same stack
+func TestLoadStack(t *testing.T) {
+	yamlPath := writeTestStack(t, "stack.yaml", testStackYAML)
+	jsonPath := writeTestStack(t, "stack.json", testStackJSON)
+	fromYAML, err := LoadStack(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadStack error = %v", err)
+	}
+	fromJSON, err := LoadStack(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadStack error = %v", err)
+	}
+	if fromYAML.SSHKeys[0].PublicKeyPath != filepath.Join(filepath.Dir(yamlPath), "deploy.pub") {
+		t.Errorf("expected the key path to be resolved next to the stack, got %v", fromYAML.SSHKeys[0].PublicKeyPath)
+	}
+	fromJSON.SSHKeys[0].PublicKeyPath = fromYAML.SSHKeys[0].PublicKeyPath
+	if diff := cmp.Diff(fromYAML, fromJSON); diff != "" {
+		t.Errorf("YAML and JSON stacks differ (-yaml +json):\n%s", diff)
+	}
+	if err := fromYAML.Validate(); err != nil {
+		t.Errorf("Validate error = %v", err)
+	}
+	// The database password can be left out of the stack and read from TF_VAR_orders_password instead
+	fromYAML.Databases[0].Password = ""
+	if err := fromYAML.Validate(); err != nil {
+		t.Errorf("Validate error without the database password = %v", err)
+	}
+}
+
+// TestLoadStack_Errors tests that malformed and invalid stacks are rejected
+func TestLoadStack_Errors(t *testing.T) {
+	files := map[string]string{
+		"Unknown Field":    "provider:\n  alias: mgc\n  regoin: br-se1\n",
+		"Empty Stack":      "",
+		"Wrong Type":       "vms: web\n",
+		"Unknown Format":   "",
+		"Missing Provider": "vms: []\n",
+	}
+	names := map[string]string{"Unknown Format": "stack.toml"}
+	for name, content := range files {
+		file := names[name]
+		if file == "" {
+			file = "stack.yaml"
+		}
+		stack, err := LoadStack(writeTestStack(t, file, content))
+		if err == nil {
+			err = stack.Validate()
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	stack, err := ParseStack([]byte(testStackJSON), "json")
+	if err != nil {
+		t.Fatalf("ParseStack error = %v", err)
+	}
+	stack.SSHKeys[0].PublicKeyPath = ""
+	stack.SSHKeys[0].PublicKey = testPublicKey
+	stack.Buckets = append(stack.Buckets, BucketInstance{Name: "assets"})
+	if err := stack.Validate(); err == nil || !strings.Contains(err.Error(), "unique") {
+		t.Errorf("expected a duplicate name error, got %v", err)
+	}
+}
+
+// TestStack_Compile tests that names are resolved to stack resources or kept as IDs of existing ones
+func TestStack_Compile(t *testing.T) {
+	stack, err := LoadStack(writeTestStack(t, "stack.yaml", testStackYAML))
+	if err != nil {
+		t.Fatalf("LoadStack error = %v", err)
+	}
+	root := t.TempDir()
+	manager := NewWorkspaceManager(root)
+	workspace, err := manager.CreateFromStack("demo", stack)
+	if err != nil {
+		t.Fatalf("CreateFromStack error = %v", err)
+	}
+	// Compare with single spaces, the alignment of the equals signs depends on the neighbouring attributes
+	squash := func(s string) string { return strings.Join(strings.Fields(s), " ") }
+	got := workspace.Render()
+	for _, want := range []string{
+		`resource "mgc_ssh_keys" "deploy"`,
+		`resource "mgc_network_vpcs" "main"`,
+		`resource "mgc_network_security_groups" "web"`,
+		`ssh_key_name = mgc_ssh_keys.deploy.name`,
+		`vpc_id = mgc_network_vpcs.main.id`,
+		`id = mgc_network_security_groups.web.id`,
+		`id = "sg-existing"`,
+		`ssh_key_name = "registered-key"`,
+		`vpc_id = "vpc-existing"`,
+		`resource "mgc_dbaas_instances" "orders"`,
+		`password = var.orders_password`,
+		`resource "mgc_object_storage_buckets" "assets"`,
+	} {
+		if !strings.Contains(squash(got), want) {
+			t.Errorf("expected %q in the workspace:\n%s", want, got)
+		}
+	}
+
+	stack.Buckets[0].Name = "Invalid"
+	if _, err := manager.CreateFromStack("broken", stack); err == nil {
+		t.Errorf("expected an error compiling an invalid stack")
+	}
+	stack.Buckets[0].Name = "assets"
+	stack.Provider.KeyID = ""
+	if _, err := manager.CreateFromStack("broken", stack); err == nil {
+		t.Errorf("expected an error compiling buckets without object storage keys")
+	}
+	if names, _ := manager.List(); len(names) != 1 {
+		t.Errorf("expected the broken workspace to be removed, got %v", names)
+	}
+}
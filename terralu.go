@@ -1,17 +1,69 @@
 package terralu
 
 import (
-	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/joaogabriel01/terralu/hcl"
 )
 
 // TerraluImpl is the concrete implementation of the Terralu and TerraformGenerator interfaces
 type TerraluImpl struct {
 	credentials *TerraluProviderInfo
-	buffer      bytes.Buffer
 	dir         string
 	mainPath    string
+	file        hcl.File
+	fs          FileSystem
+	sink        Sink
+	inMemory    bool
+	layout      Layout
+	format      Format
+	// environmentCredentials leaves the credentials to TF_VAR_ environment variables instead of terraform.tfvars
+	environmentCredentials bool
+	// secrets are the values of sensitive variables other than the credentials, such as database passwords,
+	// written to terraform.tfvars next to them
+	secrets []hcl.ObjectItem
+	// origins records the file each loaded block was read from in the multi-file layout
+	origins map[*hcl.Block]string
+	// written records the files written so far in the multi-file layout
+	written map[string]bool
+}
+
+// Option configures a Terralu instance created by New or LoadTerralu
+type Option func(*TerraluImpl)
+
+// WithDirectory sets the workspace directory; relative paths are resolved against the current directory.
+// By default New uses a random directory under the current directory
+func WithDirectory(dir string) Option {
+	return func(t *TerraluImpl) {
+		t.dir = dir
+	}
+}
+
+// WithFileSystem sets the storage the workspace is read from and written to
+func WithFileSystem(fs FileSystem) Option {
+	return func(t *TerraluImpl) {
+		t.fs = fs
+	}
+}
+
+// WithSink sets where the rendered files are written. By default they go to the workspace directory;
+// with another sink New does not create the directory. Only a DirectorySink receives terraform.tfvars,
+// other sinks leave the credentials to the TF_VAR_ variables listed by CredentialsEnvironment
+func WithSink(sink Sink) Option {
+	return func(t *TerraluImpl) {
+		t.sink = sink
+	}
+}
+
+// InMemory keeps the generated configuration in memory only; nothing is written until Save is called
+func InMemory() Option {
+	return func(t *TerraluImpl) {
+		t.inMemory = true
+	}
 }
 
 // Get returns the credentials and region
@@ -19,16 +71,78 @@ func (t *TerraluImpl) GetTerraluProviderInfo() *TerraluProviderInfo {
 	return t.credentials
 }
 
-// NewTerralu creates a new Terralu instance
-func NewTerralu(credentials *TerraluProviderInfo) Terralu {
+// New creates a new Terralu instance and, unless InMemory is given, its workspace directory
+func New(credentials *TerraluProviderInfo, opts ...Option) (Terralu, error) {
+	if credentials == nil {
+		return nil, fmt.Errorf("credentials are not set")
+	}
 	impl := &TerraluImpl{
 		credentials: credentials,
 		dir:         uuid.New().String(),
-		buffer:      bytes.Buffer{},
+		fs:          OSFileSystem{},
 	}
-	err := impl.CreateDirectory()
+	for _, opt := range opts {
+		opt(impl)
+	}
+	dir, err := impl.directory()
+	if err != nil {
+		return nil, err
+	}
+	impl.mainPath = filepath.Join(dir, mainFile)
+	if impl.inMemory || impl.sink != nil {
+		return impl, nil
+	}
+	err = impl.CreateDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return impl, nil
+}
+
+// NewTerralu creates a new Terralu instance in a random directory under the current directory.
+// It panics if the directory cannot be created; use New to handle the error
+func NewTerralu(credentials *TerraluProviderInfo) Terralu {
+	impl, err := New(credentials)
 	if err != nil {
 		panic(err)
 	}
 	return impl
 }
+
+// directory returns the absolute workspace directory
+func (t *TerraluImpl) directory() (string, error) {
+	if filepath.IsAbs(t.dir) {
+		return t.dir, nil
+	}
+	actualDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting the current directory: %w", err)
+	}
+	return filepath.Join(actualDir, t.dir), nil
+}
+
+// filesystem returns the configured FileSystem, defaulting to the local disk
+func (t *TerraluImpl) filesystem() FileSystem {
+	if t.fs == nil {
+		return OSFileSystem{}
+	}
+	return t.fs
+}
+
+// output returns the configured Sink, defaulting to the workspace directory
+func (t *TerraluImpl) output() Sink {
+	if t.sink == nil {
+		return NewDirectorySink(filepath.Dir(t.mainPath), t.filesystem())
+	}
+	return t.sink
+}
+
+// interpolation returns a string template made of the literal prefix followed by the expression
+func interpolation(prefix, expression string) hcl.Raw {
+	return hcl.Raw(strings.TrimSuffix(hcl.Quote(prefix), `"`) + "${" + expression + `}"`)
+}
+
+// resourceName returns the Terraform resource name used for a user supplied name
+func resourceName(name string) string {
+	return hcl.Identifier(name)
+}
@@ -0,0 +1,110 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNew tests the constructor options and that in-memory workspaces touch no storage
+func TestNew(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+
+	if _, err := New(nil); err == nil {
+		t.Errorf("expected an error without credentials")
+	}
+
+	dir := filepath.Join(t.TempDir(), "workspace")
+	tr, err := New(pInfo, WithDirectory(dir))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if tr.(*TerraluImpl).mainPath != filepath.Join(dir, "main.tf") {
+		t.Errorf("unexpected main path %v", tr.(*TerraluImpl).mainPath)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.tf")); err != nil {
+		t.Errorf("expected main.tf to be created: %v", err)
+	}
+
+	if _, err := New(pInfo, WithDirectory(filepath.Join(dir, "main.tf", "nested"))); err == nil {
+		t.Errorf("expected an error creating a directory under a file")
+	}
+
+	fs := NewMemoryFileSystem()
+	memoryDir := filepath.Join(t.TempDir(), "memory")
+	tr, err = New(pInfo, WithDirectory(memoryDir), WithFileSystem(fs), InMemory())
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+		t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+	}
+	if _, err := tr.UpdateVirtualMachine("web", newTestVirtualMachine("web", "large")); err != nil {
+		t.Fatalf("UpdateVirtualMachine error = %v", err)
+	}
+	if len(fs.Files()) != 0 {
+		t.Errorf("expected nothing written in memory mode, got %v", fs.Files())
+	}
+	if _, err := os.Stat(memoryDir); !os.IsNotExist(err) {
+		t.Errorf("expected no directory on disk, got %v", err)
+	}
+	if !strings.Contains(tr.Render(), `name = "large"`) {
+		t.Errorf("unexpected render:\n%s", tr.Render())
+	}
+
+	if err := tr.Save(); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	content, err := fs.ReadFile(filepath.Join(memoryDir, "main.tf"))
+	if err != nil {
+		t.Fatalf("ReadFile error = %v", err)
+	}
+	if string(content) != tr.Render() {
+		t.Errorf("saved content and Render differ:\n%s\n---\n%s", content, tr.Render())
+	}
+
+	loaded, err := LoadTerralu(memoryDir, WithFileSystem(fs))
+	if err != nil {
+		t.Fatalf("LoadTerralu error = %v", err)
+	}
+	if loaded.Render() != tr.Render() {
+		t.Errorf("loaded workspace differs:\n%s\n---\n%s", loaded.Render(), tr.Render())
+	}
+}
+
+// TestNew_WithSink tests that the workspace is written through the sink without touching the disk
+func TestNew_WithSink(t *testing.T) {
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+	dir := filepath.Join(t.TempDir(), "workspace")
+	sink := NewMemorySink()
+	tr, err := New(pInfo, WithDirectory(dir), WithSink(sink))
+	if err != nil {
+		t.Fatalf("New error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformGenericProviderConfig(); err != nil {
+		t.Fatalf("GenerateTerraformGenericProviderConfig error = %v", err)
+	}
+	if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine("web", "small")); err != nil {
+		t.Fatalf("GenerateTerraformVirtualMachineConfig error = %v", err)
+	}
+	if err := tr.RemoveResource("mgc_virtual_machine_instances", "web"); err != nil {
+		t.Fatalf("RemoveResource error = %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close error = %v", err)
+	}
+	content, err := sink.ReadFile("main.tf")
+	if err != nil {
+		t.Fatalf("ReadFile error = %v", err)
+	}
+	if string(content) != tr.Render() {
+		t.Errorf("sink content and Render differ:\n%s\n---\n%s", content, tr.Render())
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected no directory on disk, got %v", err)
+	}
+}
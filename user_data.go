@@ -0,0 +1,49 @@
+package terralu
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cloudConfigHeader is the first line cloud-init requires to treat user data as cloud-config
+const cloudConfigHeader = "#cloud-config"
+
+// readUserData returns the cloud-config of the VM, loading it from disk if needed, and makes sure it is valid
+func readUserData(fields *VirtualMachineOptionalFields) (string, error) {
+	userData := fields.UserData
+	if userData == "" && fields.UserDataPath != "" {
+		content, err := os.ReadFile(fields.UserDataPath)
+		if err != nil {
+			return "", fmt.Errorf("error reading the user data file: %w", err)
+		}
+		userData = string(content)
+	}
+	if userData == "" {
+		return "", nil
+	}
+	err := ValidateCloudConfig(userData)
+	if err != nil {
+		return "", err
+	}
+	return userData, nil
+}
+
+// ValidateCloudConfig checks that the user data is a cloud-config YAML document
+func ValidateCloudConfig(userData string) error {
+	firstLine, _, _ := strings.Cut(userData, "\n")
+	if strings.TrimSpace(firstLine) != cloudConfigHeader {
+		return fmt.Errorf("user data must start with %q", cloudConfigHeader)
+	}
+	var document map[string]any
+	err := yaml.Unmarshal([]byte(userData), &document)
+	if err != nil {
+		return fmt.Errorf("error parsing the user data: %w", err)
+	}
+	if len(document) == 0 {
+		return fmt.Errorf("user data has no cloud-config directives")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package terralu
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTerraluImpl_GenerateTerraformVirtualMachineConfig_UserData tests rendering user data into the VM resource
+func TestTerraluImpl_GenerateTerraformVirtualMachineConfig_UserData(t *testing.T) {
+	const cloudConfig = "#cloud-config\npackages:\n  - nginx\n"
+	userDataPath := filepath.Join(t.TempDir(), "user-data.yaml")
+	if err := os.WriteFile(userDataPath, []byte(cloudConfig), 0644); err != nil {
+		t.Fatalf("error writing the user data file: %v", err)
+	}
+	invalidPath := filepath.Join(t.TempDir(), "user-data.sh")
+	if err := os.WriteFile(invalidPath, []byte("#!/bin/sh\necho hi\n"), 0644); err != nil {
+		t.Fatalf("error writing the user data file: %v", err)
+	}
+	tests := []struct {
+		name    string
+		fields  VirtualMachineOptionalFields
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Without User Data",
+			fields:  VirtualMachineOptionalFields{},
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name:    "Inline Cloud Config",
+			fields:  VirtualMachineOptionalFields{UserData: cloudConfig},
+			want:    cloudConfig,
+			wantErr: false,
+		},
+		{
+			name:    "Cloud Config From File",
+			fields:  VirtualMachineOptionalFields{UserDataPath: userDataPath},
+			want:    cloudConfig,
+			wantErr: false,
+		},
+		{
+			name:    "Missing Header",
+			fields:  VirtualMachineOptionalFields{UserData: "packages:\n  - nginx\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid YAML",
+			fields:  VirtualMachineOptionalFields{UserData: "#cloud-config\npackages: [nginx\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Shell Script From File",
+			fields:  VirtualMachineOptionalFields{UserDataPath: invalidPath},
+			wantErr: true,
+		},
+		{
+			name:    "Inline And File",
+			fields:  VirtualMachineOptionalFields{UserData: cloudConfig, UserDataPath: userDataPath},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTerralu(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"})
+			defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+			vm := &VirtualMachineInstance{
+				RequiredFields: VirtualMachineRequiredFields{
+					Name:        "web",
+					MachineType: &MachineTypeSchema{Name: "cloud-bs1.xsmall"},
+					Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+					SSHKeyName:  "key",
+				},
+				OptionalFields: tt.fields,
+			}
+			got, err := tr.GenerateTerraformVirtualMachineConfig(vm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.want == "" {
+				if strings.Contains(got, "user_data") {
+					t.Errorf("expected no user_data, got %v", got)
+				}
+				return
+			}
+			want := `user_data    = "` + base64.StdEncoding.EncodeToString([]byte(tt.want)) + `"`
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
@@ -1,12 +1,12 @@
 package terralu
 
 import (
-	"bytes"
+	"encoding/base64"
 	"fmt"
-	"os"
-	"text/template"
+	"path/filepath"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/joaogabriel01/terralu/hcl"
 )
 
 // GenerateTerraformConfig generates the Terraform generic configuration
@@ -14,141 +14,161 @@ func (t *TerraluImpl) GenerateTerraformGenericProviderConfig() (string, error) {
 	if t.credentials == nil {
 		return "", fmt.Errorf("credentials are not set")
 	}
-	const terraformTemplate = `terraform {
-	required_providers {
-		mgc = {
-			source = "magalucloud/mgc"
-		}
+	if (t.credentials.KeyID == "") != (t.credentials.KeySecret == "") {
+		return "", fmt.Errorf("object storage requires both the key id and the key secret")
 	}
-}
-provider "mgc" {
-	alias    = "{{ .Alias }}"
-	region   = "{{ .Region }}"
-	api_key  = "{{ .ApiKey }}"
-}`
 
-	tmpl, err := template.New("terraform").Parse(terraformTemplate)
-	if err != nil {
-		return "", fmt.Errorf("error parsing the template: %w", err)
-	}
+	terraform := hcl.NewBlock("terraform")
+	requiredProviders := terraform.Body.AppendBlock(hcl.NewBlock("required_providers"))
+	requiredProviders.Body.SetAttribute("mgc", hcl.Object{
+		{Key: "source", Value: hcl.String("magalucloud/mgc")},
+	})
 
-	// Execute the template with the provided data
-	err = tmpl.Execute(&t.buffer, *t.credentials)
-	if err != nil {
-		return "", fmt.Errorf("error executing the template: %w", err)
+	provider := hcl.NewBlock("provider", "mgc")
+	provider.Body.
+		SetAttribute("alias", hcl.String(t.credentials.Alias)).
+		SetAttribute("region", hcl.String(t.credentials.Region)).
+		SetAttribute("api_key", variableReference(apiKeyVariable))
+	if hasObjectStorageKeys(t.credentials) {
+		provider.Body.SetAttribute("object_storage", hcl.Object{
+			{Key: "key_pair", Value: hcl.Object{
+				{Key: "key_id", Value: variableReference(keyIDVariable)},
+				{Key: "key_secret", Value: variableReference(keySecretVariable)},
+			}},
+		})
 	}
-	t.buffer.WriteString("\n")
-	manifest := t.buffer.String()
-	err = t.AppendOnFile()
-	if err != nil {
-		return "", fmt.Errorf("error appending to the file: %w", err)
-	}
-	return manifest, nil
+
+	blocks := append([]*hcl.Block{terraform}, credentialVariableBlocks(t.credentials)...)
+	return t.write(append(blocks, provider)...)
 }
 
 // GenerateTerraformConfig generates the Terraform configuration based on the VM and personal information
 func (t *TerraluImpl) GenerateTerraformVirtualMachineConfig(vm *VirtualMachineInstance) (string, error) {
+	resource, err := t.virtualMachineBlock(vm)
+	if err != nil {
+		return "", err
+	}
+	return t.write(append([]*hcl.Block{resource}, virtualMachineOutputs(vm)...)...)
+}
+
+// virtualMachineBlock validates the VM and builds its resource block
+func (t *TerraluImpl) virtualMachineBlock(vm *VirtualMachineInstance) (*hcl.Block, error) {
 	validate := validator.New()
 	err := validate.Struct(vm)
 	if err != nil {
-		return "", fmt.Errorf("error validating the virtual machine instance: %w", err)
+		return nil, fmt.Errorf("error validating the virtual machine instance: %w", err)
+	}
+	userData, err := readUserData(&vm.OptionalFields)
+	if err != nil {
+		return nil, err
 	}
 
-	const terraformTemplate = `
-resource "mgc_virtual_machine_instances" "{{ .RequiredFields.Name }}" {
-  provider      = mgc.{{ .Alias }}
-  name          = "{{ .RequiredFields.Name }}"
-  machine_type  = {
-	name  = "{{ .RequiredFields.MachineType.Name }}"
-  }
-  image         = {
-	name  = "{{ .RequiredFields.Image.Name }}"
-  }
-  
-  {{- if .OptionalFields.NameIsPrefix }}
-  name_is_prefix = true
-  {{- end }}
-  network = {
-    associate_public_ip = {{ .OptionalFields.Network.AssociatePublicIP }}
-    {{- if .OptionalFields.Network.DeletePublicIP }}
-    delete_public_ip    = {{ .OptionalFields.Network.DeletePublicIP }}
-    {{- end }}
-    {{- if .OptionalFields.Network.Interface }}
-    interface {
-      {{- range .OptionalFields.Network.Interface.SecurityGroups }}
-      security_group_ids = ["{{ .ID }}"]
-      {{- end }}
-    }
-    {{- end }}
-    {{- if .OptionalFields.Network.VPC }}
-    vpc_id = "{{ .OptionalFields.Network.VPC.ID }}"
-    {{- end }}
-  }
+	resource := t.newResource("mgc_virtual_machine_instances", vm.RequiredFields.Name)
+	name, machineType, image := setFleet(resource, vm)
+	resource.Body.
+		SetAttribute("name", name).
+		SetAttribute("machine_type", hcl.Object{
+			{Key: "name", Value: machineType},
+		}).
+		SetAttribute("image", hcl.Object{
+			{Key: "name", Value: image},
+		})
+	if vm.OptionalFields.NameIsPrefix {
+		resource.Body.SetAttribute("name_is_prefix", hcl.Bool(true))
+	}
 
-  ssh_key_name = "{{ .RequiredFields.SSHKeyName }}"
-}
-`
+	network := vm.OptionalFields.Network
+	networkObject := hcl.Object{
+		{Key: "associate_public_ip", Value: hcl.Bool(network.AssociatePublicIP)},
+	}
+	if network.DeletePublicIP {
+		networkObject = append(networkObject, hcl.ObjectItem{Key: "delete_public_ip", Value: hcl.Bool(true)})
+	}
+	if network.Interface != nil {
+		securityGroups := hcl.List{}
+		for _, sg := range network.Interface.SecurityGroups {
+			securityGroups = append(securityGroups, hcl.Object{{Key: "id", Value: sg.Expression()}})
+		}
+		networkObject = append(networkObject, hcl.ObjectItem{Key: "interface", Value: hcl.Object{
+			{Key: "security_groups", Value: securityGroups},
+		}})
+	}
+	if network.VPC != nil {
+		networkObject = append(networkObject, hcl.ObjectItem{Key: "vpc_id", Value: network.VPC.Expression()})
+	}
+	resource.Body.SetAttribute("network", networkObject)
 
-	tmpl, err := template.New("terraform").Parse(terraformTemplate)
-	if err != nil {
-		return "", fmt.Errorf("error parsing the template: %w", err)
+	if vm.RequiredFields.SSHKey != nil {
+		resource.Body.SetAttribute("ssh_key_name", vm.RequiredFields.SSHKey.Expression())
+	} else {
+		resource.Body.SetAttribute("ssh_key_name", hcl.String(vm.RequiredFields.SSHKeyName))
+	}
+	if userData != "" {
+		resource.Body.SetAttribute("user_data", hcl.String(base64.StdEncoding.EncodeToString([]byte(userData))))
 	}
+	return resource, nil
+}
 
-	// Execute the template with the provided data
-	err = tmpl.Execute(&t.buffer, struct {
-		VirtualMachineInstance
-		TerraluProviderInfo
-	}{
-		VirtualMachineInstance: *vm,
-		TerraluProviderInfo:    *t.credentials,
-	})
+// newResource creates a resource block bound to the configured provider alias
+func (t *TerraluImpl) newResource(resourceType, name string) *hcl.Block {
+	resource := hcl.NewBlock("resource", resourceType, resourceName(name))
+	resource.Body.SetAttribute("provider", hcl.Raw("mgc."+t.credentials.Alias))
+	return resource
+}
+
+// write renders the blocks, adds them to the workspace and returns the rendered manifest
+func (t *TerraluImpl) write(blocks ...*hcl.Block) (string, error) {
+	err := t.checkAddresses(blocks)
 	if err != nil {
-		return "", fmt.Errorf("error executing the template: %w", err)
+		return "", err
 	}
-	t.buffer.WriteString("\n")
-	manifest := t.buffer.String()
-	err = t.AppendOnFile()
-	if err != nil {
-		return "", fmt.Errorf("error appending to the file: %w", err)
+	manifest := string(t.encode(&hcl.File{Blocks: blocks}))
+	count := len(t.file.Blocks)
+	t.file.Blocks = append(t.file.Blocks, blocks...)
+	if !t.inMemory {
+		err := t.AppendOnFile()
+		if err != nil {
+			t.file.Blocks = t.file.Blocks[:count]
+			return "", fmt.Errorf("error appending to the file: %w", err)
+		}
 	}
 	return manifest, nil
 }
 
 // CreateDirectory creates a directory to save the Terraform configuration
 func (t *TerraluImpl) CreateDirectory() error {
-	actualDir, err := os.Getwd()
+	newDir, err := t.directory()
 	if err != nil {
-		return fmt.Errorf("error getting the current directory: %w", err)
+		return err
 	}
-	newDir := fmt.Sprintf("%s/%s/", actualDir, t.dir)
 	// Make directory if it doesn't exist
-	err = os.MkdirAll(newDir, os.ModePerm)
+	err = t.filesystem().MkdirAll(newDir)
 	if err != nil {
 		return fmt.Errorf("error creating the directory: %w", err)
 	}
-	t.mainPath = newDir + "main.tf"
-	file, err := os.Create(t.mainPath)
+	t.mainPath = filepath.Join(newDir, mainFile)
+	t.file = hcl.File{}
+	err = t.AppendOnFile()
 	if err != nil {
 		return fmt.Errorf("error creating the file: %w", err)
 	}
-	defer file.Close()
 	return nil
 }
 
-// Save saves the buffer content to a file
+// AppendOnFile writes the workspace files to the output sink
 func (t *TerraluImpl) AppendOnFile() error {
-	// Open the file in write-only mode, creating it if it doesn't exist
-	file, err := os.OpenFile(t.mainPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		return fmt.Errorf("error opening the file: %w", err)
-	}
-	defer file.Close()
-
-	// Write the buffer to the file
-	_, err = file.WriteString(t.buffer.String())
-	if err != nil {
-		return fmt.Errorf("error writing to the file: %w", err)
+	names, files := t.renderFiles()
+	for _, name := range names {
+		err := t.output().WriteFile(t.fileName(name), t.encode(files[name]))
+		if err != nil {
+			return fmt.Errorf("error writing to the file: %w", err)
+		}
+		if t.layout == MultiFileLayout {
+			if t.written == nil {
+				t.written = map[string]bool{}
+			}
+			t.written[name] = true
+		}
 	}
-	t.buffer = bytes.Buffer{}
-	return nil
+	return t.writeCredentials()
 }
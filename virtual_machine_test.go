@@ -1,7 +1,6 @@
 package terralu
 
 import (
-	"bytes"
 	"path/filepath"
 
 	"github.com/google/go-cmp/cmp"
@@ -11,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/joaogabriel01/terralu/hcl"
 )
 
 func normalizeWhitespace(s string) string {
@@ -45,10 +45,35 @@ func TestTerraluImpl_GenerateTerraformGenericProviderConfig(t *testing.T) {
 						}
 					}
 					}
+
+					variable "api_key" {
+					description = "Magalu Cloud API key"
+					type        = string
+					sensitive   = true
+					}
+
+					variable "key_id" {
+					description = "Magalu Cloud object storage key ID"
+					type        = string
+					sensitive   = true
+					}
+
+					variable "key_secret" {
+					description = "Magalu Cloud object storage key secret"
+					type        = string
+					sensitive   = true
+					}
+
 					provider "mgc" {
-					alias    = "mgc"
-					region   = "us-west-2"
-					api_key  = "api-key"
+					alias   = "mgc"
+					region  = "us-west-2"
+					api_key = var.api_key
+					object_storage = {
+						key_pair = {
+							key_id     = var.key_id
+							key_secret = var.key_secret
+						}
+					}
 					}`,
 			wantErr: false,
 		},
@@ -62,13 +87,30 @@ func TestTerraluImpl_GenerateTerraformGenericProviderConfig(t *testing.T) {
 						}
 					}
 					}
+
+					variable "api_key" {
+					description = "Magalu Cloud API key"
+					type        = string
+					sensitive   = true
+					}
+
 					provider "mgc" {
-					alias    = ""
-					region   = ""
-					api_key  = ""
+					alias   = ""
+					region  = ""
+					api_key = var.api_key
 					}`,
 			wantErr: false,
 		},
+		{
+			name: "Key ID Without Key Secret",
+			input: &TerraluProviderInfo{
+				Region: "us-west-2",
+				Alias:  "mgc",
+				ApiKey: "api-key",
+				KeyID:  "key-id",
+			},
+			wantErr: true,
+		},
 		// Adicione mais casos de teste conforme necessário
 	}
 
@@ -80,6 +122,9 @@ func TestTerraluImpl_GenerateTerraformGenericProviderConfig(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
 			}
+			if tt.wantErr {
+				return
+			}
 			// Trim whitespace to avoid differences due to extra spaces or new lines
 			got = strings.TrimSpace(got)
 			want := strings.TrimSpace(tt.want)
@@ -94,7 +139,7 @@ func TestTerraluImpl_GenerateTerraformGenericProviderConfig(t *testing.T) {
 // TestTerraluImpl_Save tests the Save method
 func TestTerraluImpl_Save(t *testing.T) {
 	type fields struct {
-		buffer  bytes.Buffer
+		file    hcl.File
 		dirname string
 	}
 	tests := []struct {
@@ -104,9 +149,9 @@ func TestTerraluImpl_Save(t *testing.T) {
 		assertion func(t *testing.T, mainPath string)
 	}{
 		{
-			name: "Save with Non-Empty Buffer",
+			name: "Save with Blocks",
 			fields: fields{
-				buffer:  *bytes.NewBufferString("test content"),
+				file:    hcl.File{Blocks: []*hcl.Block{hcl.NewBlock("terraform")}},
 				dirname: uuid.New().String(),
 			},
 			wantErr: false,
@@ -122,7 +167,7 @@ func TestTerraluImpl_Save(t *testing.T) {
 		{
 			name: "Save with Invalid dirname",
 			fields: fields{
-				buffer:  *bytes.NewBufferString("invalid dirname test"),
+				file:    hcl.File{Blocks: []*hcl.Block{hcl.NewBlock("terraform")}},
 				dirname: string([]byte{0x00, 0x01, 0x02}), // Invalid dirname
 			},
 			wantErr: true,
@@ -139,7 +184,7 @@ func TestTerraluImpl_Save(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tr := &TerraluImpl{
-				buffer:   tt.fields.buffer,
+				file:     tt.fields.file,
 				mainPath: filepath.Join(tt.fields.dirname, "main.tf"),
 			}
 
@@ -245,7 +290,7 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
 						},
 						SSHKeyName: "test-key",
 					},
-					OptionalFields: VirtualMachineOptionalFields{},
+					OptionalFields: VirtualMachineOptionalFields{SkipOutputs: true},
 				},
 				pInfo: &TerraluProviderInfo{
 					Alias:     "test",
@@ -259,10 +304,10 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
           provider      = mgc.test
           name          = "test"
           machine_type  = {
-        	name  = "t2.micro"
+            name = "t2.micro"
           }
           image         = {
-        	name  = "ami-name-123456"
+            name = "ami-name-123456"
           }
           network = {
             associate_public_ip = false
@@ -310,6 +355,7 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
 								ID: "vpc-abcdef",
 							},
 						},
+						SkipOutputs: true,
 					},
 				},
 				pInfo: &TerraluProviderInfo{
@@ -324,17 +370,19 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
           provider      = mgc.prod
           name          = "prod-vm"
           machine_type  = {
-        	name  = "m5.large"
+            name = "m5.large"
           }
           image         = {
-        	name  = "ami-prod-789012"
+            name = "ami-prod-789012"
           }
           name_is_prefix = true
           network = {
             associate_public_ip = true
-            interface {
-              security_group_ids = ["sg-12345"]
-              security_group_ids = ["sg-67890"]
+            interface = {
+              security_groups = [
+                { id = "sg-12345" },
+                { id = "sg-67890" },
+              ]
             }
             vpc_id = "vpc-abcdef"
           }
@@ -398,7 +446,7 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
 						SSHKeyName: "test-key",
 					},
 					// Inject nil to cause execution error
-					OptionalFields: VirtualMachineOptionalFields{},
+					OptionalFields: VirtualMachineOptionalFields{SkipOutputs: true},
 				},
 				pInfo: &TerraluProviderInfo{
 					Alias:     "test",
@@ -413,10 +461,10 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
           provider      = mgc.test
           name          = "test"
           machine_type  = {
-        	name  = "t2.micro"
+            name = "t2.micro"
           }
           image         = {
-        	name  = "ami-name-123456"
+            name = "ami-name-123456"
           }
           network = {
             associate_public_ip = false
@@ -452,3 +500,31 @@ func TestTerraluImpl_GenerateTerraformVirtualMachineConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestTerraluImpl_GenerateTerraformVirtualMachineConfig_Escaping tests that user input cannot break the generated HCL
+func TestTerraluImpl_GenerateTerraformVirtualMachineConfig_Escaping(t *testing.T) {
+	vm := &VirtualMachineInstance{
+		RequiredFields: VirtualMachineRequiredFields{
+			Name:        `my "vm"`,
+			MachineType: &MachineTypeSchema{Name: "cloud-bs1.xsmall"},
+			Image:       &ImageSchema{Name: "cloud-ubuntu-22.04 LTS"},
+			SSHKeyName:  "key ${injected}",
+		},
+	}
+	tr := NewTerralu(&TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"})
+	defer os.RemoveAll(filepath.Dir(tr.(*TerraluImpl).mainPath))
+
+	got, err := tr.GenerateTerraformVirtualMachineConfig(vm)
+	if err != nil {
+		t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+	}
+	for _, want := range []string{
+		`resource "mgc_virtual_machine_instances" "my__vm_" {`,
+		`name     = "my \"vm\""`,
+		`ssh_key_name = "key $${injected}"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package terralu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// WorkspaceRootEnv overrides the default directory where named workspaces are kept
+const WorkspaceRootEnv = "TERRALU_WORKSPACES"
+
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// WorkspaceManager keeps named, reusable workspaces under a root directory
+type WorkspaceManager struct {
+	root string
+}
+
+// NewWorkspaceManager creates a manager for the workspaces under root
+func NewWorkspaceManager(root string) *WorkspaceManager {
+	return &WorkspaceManager{root: root}
+}
+
+// DefaultWorkspaceRoot returns $TERRALU_WORKSPACES, falling back to ~/.terralu/workspaces
+func DefaultWorkspaceRoot() (string, error) {
+	if root := os.Getenv(WorkspaceRootEnv); root != "" {
+		return root, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting the home directory: %w", err)
+	}
+	return filepath.Join(home, ".terralu", "workspaces"), nil
+}
+
+// Root returns the directory holding the workspaces
+func (m *WorkspaceManager) Root() string {
+	return m.root
+}
+
+// Path returns the directory of the named workspace
+func (m *WorkspaceManager) Path(name string) (string, error) {
+	if !workspaceNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid workspace name %q", name)
+	}
+	root, err := filepath.Abs(m.root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving the workspace root: %w", err)
+	}
+	return filepath.Join(root, name), nil
+}
+
+// List returns the names of the existing workspaces in alphabetical order
+func (m *WorkspaceManager) List() ([]string, error) {
+	entries, err := os.ReadDir(m.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the workspace root: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !workspaceNamePattern.MatchString(entry.Name()) {
+			continue
+		}
+		if isWorkspace(filepath.Join(m.root, entry.Name())) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Exists reports whether the named workspace exists
+func (m *WorkspaceManager) Exists(name string) bool {
+	dir, err := m.Path(name)
+	return err == nil && isWorkspace(dir)
+}
+
+// isWorkspace reports whether the directory holds a workspace in either layout and format
+func isWorkspace(dir string) bool {
+	for _, name := range []string{mainFile, providersFile, mainFile + ".json", providersFile + ".json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// tracksResources reports whether a *.tfstate file in the directory lists any resource.
+// A state file that cannot be read is reported as an error, so callers stay on the safe side
+func tracksResources(dir string) (bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tfstate"))
+	if err != nil {
+		return false, err
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("error reading the state: %w", err)
+		}
+		var state struct {
+			Resources []json.RawMessage `json:"resources"`
+		}
+		err = json.Unmarshal(content, &state)
+		if err != nil {
+			return false, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		if len(state.Resources) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Create creates the named workspace and writes its provider configuration; the options are passed to New.
+// Managed workspaces are directories on disk, so WithFileSystem, WithSink and InMemory are rejected
+func (m *WorkspaceManager) Create(name string, credentials *TerraluProviderInfo, opts ...Option) (Terralu, error) {
+	dir, err := m.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	err = checkManagedOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("workspace %q already exists", name)
+	}
+	impl, err := New(credentials, append(opts, WithDirectory(dir))...)
+	if err != nil {
+		return nil, err
+	}
+	_, err = impl.GenerateTerraformGenericProviderConfig()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return impl, nil
+}
+
+// checkManagedOptions rejects the options that would write the workspace somewhere else than its directory,
+// where the manager looks for it and cleans it up
+func checkManagedOptions(opts []Option) error {
+	probe := &TerraluImpl{}
+	for _, opt := range opts {
+		opt(probe)
+	}
+	if _, ok := probe.fs.(OSFileSystem); probe.fs != nil && !ok {
+		return fmt.Errorf("managed workspaces are kept on disk and cannot use another file system")
+	}
+	if probe.sink != nil || probe.inMemory {
+		return fmt.Errorf("managed workspaces are written to their directory and cannot use another sink")
+	}
+	return nil
+}
+
+// CreateFromStack creates the named workspace from the stack provider and compiles the stack into it.
+// The workspace is removed again if the stack does not compile
+func (m *WorkspaceManager) CreateFromStack(name string, stack *Stack, opts ...Option) (Terralu, error) {
+	err := stack.Validate()
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := m.Create(name, &stack.Provider, opts...)
+	if err != nil {
+		return nil, err
+	}
+	err = stack.Compile(workspace)
+	if err != nil {
+		m.Delete(name)
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// Open loads the named workspace
+func (m *WorkspaceManager) Open(name string) (Terralu, error) {
+	dir, err := m.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTerralu(dir)
+}
+
+// Delete removes the named workspace and everything in it. A workspace whose Terraform state still tracks
+// resources is refused, because deleting the state would orphan them; destroy the resources first
+func (m *WorkspaceManager) Delete(name string) error {
+	dir, err := m.Path(name)
+	if err != nil {
+		return err
+	}
+	if !isWorkspace(dir) {
+		return fmt.Errorf("workspace %q not found", name)
+	}
+	tracked, err := tracksResources(dir)
+	if err != nil {
+		return fmt.Errorf("workspace %q was not deleted: %w", name, err)
+	}
+	if tracked {
+		return fmt.Errorf("workspace %q has Terraform state with live resources, destroy them before deleting it", name)
+	}
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return fmt.Errorf("error deleting the workspace: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package terralu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWorkspaceManager tests creating, listing, opening and deleting named workspaces
+func TestWorkspaceManager(t *testing.T) {
+	manager := NewWorkspaceManager(filepath.Join(t.TempDir(), "workspaces"))
+	pInfo := &TerraluProviderInfo{Alias: "test", Region: "br-se1", ApiKey: "access"}
+
+	names, err := manager.List()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("expected no workspaces, got %v (%v)", names, err)
+	}
+
+	for _, name := range []string{"staging", "prod"} {
+		tr, err := manager.Create(name, pInfo)
+		if err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+		if _, err := tr.GenerateTerraformVirtualMachineConfig(newTestVirtualMachine(name+"-vm", "small")); err != nil {
+			t.Fatalf("Err on GenerateTerraformVirtualMachineConfig: %v", err)
+		}
+	}
+	if _, err := manager.Create("prod", pInfo); err == nil {
+		t.Errorf("expected an error creating an existing workspace")
+	}
+	for _, name := range []string{"../escape", "", "a/b", ".hidden"} {
+		if _, err := manager.Create(name, pInfo); err == nil {
+			t.Errorf("expected an error for workspace name %q", name)
+		}
+	}
+
+	for _, opt := range []Option{WithFileSystem(NewMemoryFileSystem()), WithSink(NewWriterSink(os.Stdout)), InMemory()} {
+		if _, err := manager.Create("memory", pInfo, opt); err == nil || manager.Exists("memory") {
+			t.Errorf("expected an error creating a workspace away from its directory, got %v", err)
+		}
+	}
+
+	names, err = manager.List()
+	if err != nil {
+		t.Fatalf("List error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"prod", "staging"}, names); diff != "" {
+		t.Errorf("workspaces mismatch (-want +got):\n%s", diff)
+	}
+
+	tr, err := manager.Open("prod")
+	if err != nil {
+		t.Fatalf("Open error = %v", err)
+	}
+	if diff := cmp.Diff(pInfo, tr.GetTerraluProviderInfo()); diff != "" {
+		t.Errorf("provider info mismatch (-want +got):\n%s", diff)
+	}
+	vms, err := tr.ListVirtualMachines()
+	if err != nil || len(vms) != 1 || vms[0].RequiredFields.Name != "prod-vm" {
+		t.Errorf("unexpected virtual machines %v (%v)", vms, err)
+	}
+
+	stagingDir, _ := manager.Path("staging")
+	state := filepath.Join(stagingDir, "terraform.tfstate")
+	if err := os.WriteFile(state, []byte(`{"version": 4, "resources": [{"type": "mgc_virtual_machine_instances"}]}`), 0644); err != nil {
+		t.Fatalf("error writing the state: %v", err)
+	}
+	if err := manager.Delete("staging"); err == nil || !manager.Exists("staging") {
+		t.Errorf("expected deleting a workspace with live resources to be refused, got %v", err)
+	}
+	if err := os.WriteFile(state, []byte(`{"version": 4, "resources": []}`), 0644); err != nil {
+		t.Fatalf("error writing the state: %v", err)
+	}
+	if err := manager.Delete("staging"); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+	if err := manager.Delete("staging"); err == nil {
+		t.Errorf("expected an error deleting a missing workspace")
+	}
+	if _, err := manager.Open("staging"); err == nil {
+		t.Errorf("expected an error opening a deleted workspace")
+	}
+	if !manager.Exists("prod") || manager.Exists("staging") || manager.Exists("../escape") {
+		t.Errorf("Exists should only report the prod workspace")
+	}
+	names, _ = manager.List()
+	if diff := cmp.Diff([]string{"prod"}, names); diff != "" {
+		t.Errorf("workspaces mismatch (-want +got):\n%s", diff)
+	}
+}